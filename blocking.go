@@ -0,0 +1,290 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// waitChange returns a channel that is closed the next time the stack is modified.
+// Callers must hold the write lock; the channel should be waited on after the
+// lock is released.
+func (s *stack[T]) waitChange() <-chan struct{} {
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+
+	return s.changed
+}
+
+// broadcast wakes all goroutines waiting on a channel returned by waitChange.
+// Callers must hold the write lock.
+func (s *stack[T]) broadcast() {
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+
+	if s.ready != nil {
+		hasItems := len(s.items) > 0
+		if hasItems && !s.hadItems {
+			select {
+			case s.ready <- struct{}{}:
+			default:
+			}
+		}
+		s.hadItems = hasItems
+	}
+}
+
+func (s *stack[T]) Notify() <-chan struct{} {
+	s.lock()
+	defer s.unlock()
+
+	if s.ready == nil {
+		s.ready = make(chan struct{}, 1)
+		s.hadItems = len(s.items) > 0
+	}
+
+	return s.ready
+}
+
+func (s *sharded[T]) Notify() <-chan struct{} {
+	s.lockAll()
+	defer s.unlockAll()
+
+	// Every shard signals the same channel when it goes from empty to
+	// non-empty. This may signal while other shards hold items, but never
+	// misses the whole stack becoming non-empty.
+	ready := s.shards[0].ready
+	if ready == nil {
+		ready = make(chan struct{}, 1)
+		for _, sh := range s.shards {
+			sh.ready = ready
+			sh.hadItems = len(sh.items) > 0
+		}
+	}
+
+	return ready
+}
+
+func (s *stack[T]) BlockingPop(ctx context.Context) (T, error) {
+	s.lock()
+	defer s.release()
+
+	for s.sealed() != nil || len(s.items) == 0 {
+		if err := s.sealed(); err != nil {
+			var zero T
+			return zero, err
+		}
+		changed := s.waitChange()
+
+		s.unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			s.lock()
+			var zero T
+			return zero, ctx.Err()
+		}
+		s.lock()
+	}
+
+	result := s.pop()
+	s.didPop(result)
+	s.broadcast()
+
+	return result, nil
+}
+
+func (s *stack[T]) BlockingPush(ctx context.Context, val T) error {
+	s.lock()
+	defer s.release()
+
+	for {
+		if err := s.sealed(); err != nil {
+			return err
+		}
+		if dup, err := s.duplicate(val); dup {
+			return err
+		}
+		if s.reserve(val) {
+			break
+		}
+		changed := s.waitChange()
+
+		s.unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			s.lock()
+			return ctx.Err()
+		}
+		s.lock()
+	}
+
+	s.push(val)
+	s.didPush(val)
+	s.broadcast()
+
+	return nil
+}
+
+func (s *stack[T]) WaitUntilEmpty(ctx context.Context) error {
+	return s.waitUntil(ctx, func() bool { return len(s.items) == 0 })
+}
+
+func (s *sharded[T]) WaitUntilEmpty(ctx context.Context) error {
+	return s.wait(ctx, func() bool { return s.Size() == 0 })
+}
+
+func (s *stack[T]) WaitForSize(ctx context.Context, n int) error {
+	return s.waitUntil(ctx, func() bool { return len(s.items) >= n })
+}
+
+func (s *sharded[T]) WaitForSize(ctx context.Context, n int) error {
+	return s.wait(ctx, func() bool { return s.Size() >= n })
+}
+
+// waitUntil blocks until cond, which is called under the write lock, reports
+// true. Returns ErrClosed or ErrFrozen if the stack is sealed first, or
+// ctx.Err() if the context is done first.
+func (s *stack[T]) waitUntil(ctx context.Context, cond func() bool) error {
+	s.lock()
+	defer s.unlock()
+
+	for !cond() {
+		if err := s.sealed(); err != nil {
+			return err
+		}
+		changed := s.waitChange()
+
+		s.unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			s.lock()
+			return ctx.Err()
+		}
+		s.lock()
+	}
+
+	return nil
+}
+
+func (s *stack[T]) PopWithTimeout(d time.Duration) (T, error) {
+	return popWithTimeout(s, d)
+}
+
+func (s *sharded[T]) PopWithTimeout(d time.Duration) (T, error) {
+	return popWithTimeout(s, d)
+}
+
+// popWithTimeout calls BlockingPop on s with a context that expires after d,
+// translating the expiry into ErrTimeout. The context, and with it the timer,
+// is released as soon as BlockingPop returns.
+func popWithTimeout[T any](s Stack[T], d time.Duration) (T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	val, err := s.BlockingPop(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return val, ErrTimeout
+	}
+
+	return val, err
+}
+
+func (s *stack[T]) PushWithBackoff(ctx context.Context, val T, backoff func(attempt int) time.Duration) error {
+	return pushWithBackoff(ctx, s, val, backoff)
+}
+
+func (s *sharded[T]) PushWithBackoff(ctx context.Context, val T, backoff func(attempt int) time.Duration) error {
+	return pushWithBackoff(ctx, s, val, backoff)
+}
+
+// pushWithBackoff calls Push on s until it succeeds or fails with an error
+// other than ErrOverflow, sleeping for backoff(attempt) after each failed
+// attempt, or until ctx is done.
+func pushWithBackoff[T any](ctx context.Context, s Stack[T], val T, backoff func(attempt int) time.Duration) error {
+	for attempt := 1; ; attempt++ {
+		err := s.Push(val)
+		if !errors.Is(err, ErrOverflow) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *stack[T]) Close() error {
+	s.lock()
+	defer s.unlock()
+
+	if s.closed.Load() {
+		return ErrClosed
+	}
+
+	s.closed.Store(true)
+	s.broadcast()
+	if s.done != nil {
+		close(s.done)
+	}
+
+	return nil
+}
+
+func (s *sharded[T]) Close() error {
+	s.lockAll()
+	if s.closed.Load() {
+		s.unlockAll()
+		return ErrClosed
+	}
+
+	s.closed.Store(true)
+	for _, sh := range s.shards {
+		sh.closed.Store(true)
+		sh.broadcast()
+	}
+	if s.done != nil {
+		close(s.done)
+	}
+	s.unlockAll()
+
+	s.notify()
+
+	return nil
+}
+
+func (s *stack[T]) Freeze() {
+	s.lock()
+	defer s.unlock()
+
+	if !s.frozen.Load() {
+		s.frozen.Store(true)
+		s.broadcast()
+	}
+}
+
+func (s *sharded[T]) Freeze() {
+	s.lockAll()
+	if s.frozen.Load() {
+		s.unlockAll()
+		return
+	}
+
+	s.frozen.Store(true)
+	for _, sh := range s.shards {
+		sh.frozen.Store(true)
+		sh.broadcast()
+	}
+	s.unlockAll()
+
+	s.notify()
+}
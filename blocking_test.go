@@ -0,0 +1,582 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlockingPop(t *testing.T) {
+	t.Run("returns available item", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2}))
+
+		val, err := s.BlockingPop(context.Background())
+		if err != nil {
+			t.Errorf("BlockingPop() error = %v, want nil", err)
+		}
+		if val != 2 {
+			t.Errorf("BlockingPop() = %d, want 2", val)
+		}
+	})
+
+	t.Run("waits for push", func(t *testing.T) {
+		s := New[int]()
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = s.Push(42)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		val, err := s.BlockingPop(ctx)
+		if err != nil {
+			t.Errorf("BlockingPop() error = %v, want nil", err)
+		}
+		if val != 42 {
+			t.Errorf("BlockingPop() = %d, want 42", val)
+		}
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		s := New[int]()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		val, err := s.BlockingPop(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("BlockingPop() error = %v, want context.DeadlineExceeded", err)
+		}
+		if val != 0 {
+			t.Errorf("BlockingPop() value = %d, want 0 (zero value)", val)
+		}
+
+		// The stack must remain usable after a cancelled wait
+		_ = s.Push(1)
+		if val, _ := s.Pop(); val != 1 {
+			t.Errorf("Pop() after cancelled BlockingPop() = %d, want 1", val)
+		}
+	})
+
+	t.Run("multiple consumers", func(t *testing.T) {
+		s := New[int]()
+		const numConsumers = 10
+
+		var wg sync.WaitGroup
+		results := make(chan int, numConsumers)
+		for i := 0; i < numConsumers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				if val, err := s.BlockingPop(ctx); err == nil {
+					results <- val
+				}
+			}()
+		}
+
+		for i := 0; i < numConsumers; i++ {
+			_ = s.Push(i)
+		}
+
+		wg.Wait()
+		close(results)
+
+		count := 0
+		for range results {
+			count++
+		}
+		if count != numConsumers {
+			t.Errorf("BlockingPop() consumers received %d items, want %d", count, numConsumers)
+		}
+	})
+}
+
+func TestPopWithTimeout(t *testing.T) {
+	for name, s := range map[string]Stack[int]{"plain": New[int](), "sharded": NewSharded[int](2)} {
+		t.Run(name, func(t *testing.T) {
+			_ = s.Push(1)
+			if val, err := s.PopWithTimeout(time.Second); val != 1 || err != nil {
+				t.Errorf("PopWithTimeout() with item available = %d, %v, want 1, nil", val, err)
+			}
+
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				_ = s.Push(42)
+			}()
+			if val, err := s.PopWithTimeout(time.Second); val != 42 || err != nil {
+				t.Errorf("PopWithTimeout() waiting for push = %d, %v, want 42, nil", val, err)
+			}
+
+			start := time.Now()
+			val, err := s.PopWithTimeout(20 * time.Millisecond)
+			if !errors.Is(err, ErrTimeout) || val != 0 {
+				t.Errorf("PopWithTimeout() on empty stack = %d, %v, want 0, ErrTimeout", val, err)
+			}
+			if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+				t.Errorf("PopWithTimeout() returned after %v, want at least 20ms", elapsed)
+			}
+
+			if _, err := s.PopWithTimeout(0); !errors.Is(err, ErrTimeout) {
+				t.Errorf("PopWithTimeout(0) on empty stack error = %v, want ErrTimeout", err)
+			}
+		})
+	}
+}
+
+func TestPushWithBackoff(t *testing.T) {
+	for name, newStack := range stackKinds {
+		t.Run(name, func(t *testing.T) {
+			s := newStack(WithCapacity[int](2), WithItems([]int{1, 2}))
+
+			var attempts []int
+			backoff := func(attempt int) time.Duration {
+				attempts = append(attempts, attempt)
+				if attempt == 3 {
+					_, _ = s.Pop()
+				}
+				return time.Millisecond
+			}
+			if err := s.PushWithBackoff(context.Background(), 3, backoff); err != nil {
+				t.Fatalf("PushWithBackoff() error = %v, want nil", err)
+			}
+			if !slices.Equal(attempts, []int{1, 2, 3}) {
+				t.Errorf("backoff attempts = %v, want [1 2 3]", attempts)
+			}
+			if !Contains(s, 3) {
+				t.Errorf("ToSlice() after PushWithBackoff() = %v, want 3 included", s.ToSlice())
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := s.PushWithBackoff(ctx, 4, func(int) time.Duration { return time.Hour })
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("PushWithBackoff() on full stack error = %v, want context.DeadlineExceeded", err)
+			}
+
+			_ = s.Close()
+			if err := s.PushWithBackoff(context.Background(), 4, backoff); !errors.Is(err, ErrClosed) {
+				t.Errorf("PushWithBackoff() on closed stack error = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestWaitUntilEmpty(t *testing.T) {
+	for name, newStack := range stackKinds {
+		t.Run(name, func(t *testing.T) {
+			if err := newStack().WaitUntilEmpty(context.Background()); err != nil {
+				t.Errorf("WaitUntilEmpty() on empty stack error = %v, want nil", err)
+			}
+
+			s := newStack(WithItems([]int{1, 2, 3}))
+			go func() {
+				for range 3 {
+					time.Sleep(5 * time.Millisecond)
+					_, _ = s.Pop()
+				}
+			}()
+			if err := s.WaitUntilEmpty(context.Background()); err != nil {
+				t.Fatalf("WaitUntilEmpty() error = %v, want nil", err)
+			}
+			if size := s.Size(); size != 0 {
+				t.Errorf("Size() after WaitUntilEmpty() = %d, want 0", size)
+			}
+
+			_ = s.Push(1)
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if err := s.WaitUntilEmpty(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("WaitUntilEmpty() with items left error = %v, want context.DeadlineExceeded", err)
+			}
+
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				_ = s.Close()
+			}()
+			if err := s.WaitUntilEmpty(context.Background()); !errors.Is(err, ErrClosed) {
+				t.Errorf("WaitUntilEmpty() when closed error = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestWaitForSize(t *testing.T) {
+	for name, newStack := range stackKinds {
+		t.Run(name, func(t *testing.T) {
+			s := newStack(WithItems([]int{1}))
+			if err := s.WaitForSize(context.Background(), 1); err != nil {
+				t.Errorf("WaitForSize(1) with one item error = %v, want nil", err)
+			}
+
+			go func() {
+				for i := range 3 {
+					time.Sleep(5 * time.Millisecond)
+					_ = s.Push(i)
+				}
+			}()
+			if err := s.WaitForSize(context.Background(), 3); err != nil {
+				t.Fatalf("WaitForSize(3) error = %v, want nil", err)
+			}
+			if size := s.Size(); size < 3 {
+				t.Errorf("Size() after WaitForSize(3) = %d, want at least 3", size)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if err := s.WaitForSize(ctx, 10); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("WaitForSize(10) error = %v, want context.DeadlineExceeded", err)
+			}
+
+			_ = s.Close()
+			if err := s.WaitForSize(context.Background(), 10); !errors.Is(err, ErrClosed) {
+				t.Errorf("WaitForSize(10) on closed stack error = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	signalled := func(ch <-chan struct{}) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+
+	for name, s := range map[string]Stack[int]{"plain": New[int](), "sharded": NewSharded[int](2)} {
+		t.Run(name, func(t *testing.T) {
+			ch := s.Notify()
+			if s.Notify() != ch {
+				t.Error("Notify() returned a different channel on the second call")
+			}
+			if signalled(ch) {
+				t.Error("Notify() signalled before any push")
+			}
+
+			_ = s.Push(1)
+			_ = s.Push(2)
+			if !signalled(ch) {
+				t.Error("no signal after pushing onto an empty stack")
+			}
+			if signalled(ch) {
+				t.Error("signals for a single transition did not coalesce")
+			}
+
+			s.Clear()
+			_ = s.PushMany(3, 4)
+			if !signalled(ch) {
+				t.Error("no signal after PushMany onto an emptied stack")
+			}
+		})
+	}
+
+	t.Run("wakes select", func(t *testing.T) {
+		s := New[int]()
+		ch := s.Notify()
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = s.Push(42)
+		}()
+
+		select {
+		case <-ch:
+			if val, ok := s.TryPop(); !ok || val != 42 {
+				t.Errorf("TryPop() after signal = %d, %v, want 42, true", val, ok)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for signal")
+		}
+	})
+
+	t.Run("existing items", func(t *testing.T) {
+		s := New[int](WithItems([]int{1}))
+		ch := s.Notify()
+
+		_ = s.Push(2)
+		if signalled(ch) {
+			t.Error("signal for a push onto a non-empty stack")
+		}
+	})
+}
+
+func TestClose(t *testing.T) {
+	for name, newStack := range stackKinds {
+		t.Run(name, func(t *testing.T) {
+			t.Run("wakes blocked goroutines", func(t *testing.T) {
+				empty := newStack()
+				full := newStack(WithCapacity[int](2), WithItems([]int{1, 2}))
+
+				errs := make(chan error, 3)
+				go func() {
+					_, err := empty.BlockingPop(context.Background())
+					errs <- err
+				}()
+				go func() {
+					_, err := empty.PopWithTimeout(time.Minute)
+					errs <- err
+				}()
+				go func() {
+					errs <- full.BlockingPush(context.Background(), 3)
+				}()
+
+				time.Sleep(10 * time.Millisecond)
+				if err := empty.Close(); err != nil {
+					t.Fatalf("Close() error = %v, want nil", err)
+				}
+				if err := full.Close(); err != nil {
+					t.Fatalf("Close() error = %v, want nil", err)
+				}
+
+				for range 3 {
+					select {
+					case err := <-errs:
+						if !errors.Is(err, ErrClosed) {
+							t.Errorf("blocked operation error = %v, want ErrClosed", err)
+						}
+					case <-time.After(time.Second):
+						t.Fatal("blocked operation was not woken by Close()")
+					}
+				}
+			})
+
+			t.Run("operations fail fast", func(t *testing.T) {
+				s := newStack(WithItems([]int{1, 2}))
+				want := s.ToSlice()
+				_ = s.Close()
+
+				if err := s.Push(3); !errors.Is(err, ErrClosed) {
+					t.Errorf("Push() error = %v, want ErrClosed", err)
+				}
+				if err := s.PushMany(3, 4); !errors.Is(err, ErrClosed) {
+					t.Errorf("PushMany() error = %v, want ErrClosed", err)
+				}
+				if _, err := s.Pop(); !errors.Is(err, ErrClosed) {
+					t.Errorf("Pop() error = %v, want ErrClosed", err)
+				}
+				if err := s.Dup(); !errors.Is(err, ErrClosed) {
+					t.Errorf("Dup() error = %v, want ErrClosed", err)
+				}
+				if err := s.Rotate(1); !errors.Is(err, ErrClosed) {
+					t.Errorf("Rotate() error = %v, want ErrClosed", err)
+				}
+				if err := s.UpdateTop(func(*int) error { return nil }); !errors.Is(err, ErrClosed) {
+					t.Errorf("UpdateTop() error = %v, want ErrClosed", err)
+				}
+				if _, err := s.PopUntil(func(int) bool { return true }); !errors.Is(err, ErrClosed) {
+					t.Errorf("PopUntil() error = %v, want ErrClosed", err)
+				}
+				if err := s.Transaction(func(TxStack[int]) error { return nil }); !errors.Is(err, ErrClosed) {
+					t.Errorf("Transaction() error = %v, want ErrClosed", err)
+				}
+				if s.TryPush(3) {
+					t.Error("TryPush() = true, want false")
+				}
+				if _, ok := s.TryPop(); ok {
+					t.Error("TryPop() ok = true, want false")
+				}
+				if got := s.PopOr(-1); got != -1 {
+					t.Errorf("PopOr(-1) = %d, want -1", got)
+				}
+				for range s.Drain() {
+					t.Error("Drain() yielded an item")
+				}
+				if err := s.Swap(); !errors.Is(err, ErrClosed) {
+					t.Errorf("Swap() error = %v, want ErrClosed", err)
+				}
+				if err := s.SwapAt(0, 1); !errors.Is(err, ErrClosed) {
+					t.Errorf("SwapAt() error = %v, want ErrClosed", err)
+				}
+				if _, err := s.SetCapacity(1); !errors.Is(err, ErrClosed) {
+					t.Errorf("SetCapacity() error = %v, want ErrClosed", err)
+				}
+				if _, _, err := s.SplitAt(1); !errors.Is(err, ErrClosed) {
+					t.Errorf("SplitAt() error = %v, want ErrClosed", err)
+				}
+				if err := s.Restore(New[int]().Snapshot()); !errors.Is(err, ErrClosed) {
+					t.Errorf("Restore() error = %v, want ErrClosed", err)
+				}
+				s.Clear()
+				s.Reverse()
+				if n := s.RetainFunc(func(int) bool { return false }); n != 0 {
+					t.Errorf("RetainFunc() = %d, want 0", n)
+				}
+
+				if got := s.ToSlice(); !slices.Equal(got, want) {
+					t.Errorf("ToSlice() after Close() = %v, want %v", got, want)
+				}
+				if st := s.Stats(); st.Overflows != 0 || st.Underflows != 0 {
+					t.Errorf("Stats() = %+v, want closed failures not counted", st)
+				}
+			})
+
+			t.Run("double close", func(t *testing.T) {
+				s := newStack()
+				_ = s.Close()
+				if err := s.Close(); !errors.Is(err, ErrClosed) {
+					t.Errorf("second Close() error = %v, want ErrClosed", err)
+				}
+			})
+		})
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	for name, newStack := range stackKinds {
+		t.Run(name, func(t *testing.T) {
+			t.Run("modifications fail", func(t *testing.T) {
+				s := newStack(WithItems([]int{1, 2, 3}))
+				want := s.ToSlice()
+				s.Freeze()
+
+				if err := s.Push(4); !errors.Is(err, ErrFrozen) {
+					t.Errorf("Push() error = %v, want ErrFrozen", err)
+				}
+				if _, err := s.Pop(); !errors.Is(err, ErrFrozen) {
+					t.Errorf("Pop() error = %v, want ErrFrozen", err)
+				}
+				if err := s.Swap(); !errors.Is(err, ErrFrozen) {
+					t.Errorf("Swap() error = %v, want ErrFrozen", err)
+				}
+				if _, err := s.SetCapacity(1); !errors.Is(err, ErrFrozen) {
+					t.Errorf("SetCapacity() error = %v, want ErrFrozen", err)
+				}
+				if err := s.Restore(New[int]().Snapshot()); !errors.Is(err, ErrFrozen) {
+					t.Errorf("Restore() error = %v, want ErrFrozen", err)
+				}
+				if _, err := s.DrainTo(New[int](), -1); !errors.Is(err, ErrFrozen) {
+					t.Errorf("DrainTo() error = %v, want ErrFrozen", err)
+				}
+				if s.TryPush(4) {
+					t.Error("TryPush() = true, want false")
+				}
+				s.Clear()
+				s.Reverse()
+				if n := s.RetainFunc(func(int) bool { return false }); n != 0 {
+					t.Errorf("RetainFunc() = %d, want 0", n)
+				}
+				if items := s.DrainAll(); items != nil {
+					t.Errorf("DrainAll() = %v, want nil", items)
+				}
+
+				if got := s.ToSlice(); !slices.Equal(got, want) {
+					t.Errorf("ToSlice() after Freeze() = %v, want %v", got, want)
+				}
+				if top, err := s.Peek(); err != nil || top != want[len(want)-1] {
+					t.Errorf("Peek() = %d, %v, want %d, nil", top, err, want[len(want)-1])
+				}
+				if st := s.Stats(); st.Overflows != 0 || st.Underflows != 0 {
+					t.Errorf("Stats() = %+v, want frozen failures not counted", st)
+				}
+			})
+
+			t.Run("wakes blocked goroutines", func(t *testing.T) {
+				s := newStack()
+
+				errs := make(chan error, 1)
+				go func() {
+					_, err := s.BlockingPop(context.Background())
+					errs <- err
+				}()
+
+				time.Sleep(10 * time.Millisecond)
+				s.Freeze()
+
+				select {
+				case err := <-errs:
+					if !errors.Is(err, ErrFrozen) {
+						t.Errorf("BlockingPop() error = %v, want ErrFrozen", err)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("BlockingPop() was not woken by Freeze()")
+				}
+			})
+
+			t.Run("double freeze and close", func(t *testing.T) {
+				s := newStack(WithItems([]int{1}))
+				s.Freeze()
+				s.Freeze()
+				if _, err := s.Pop(); !errors.Is(err, ErrFrozen) {
+					t.Errorf("Pop() after second Freeze() error = %v, want ErrFrozen", err)
+				}
+
+				if err := s.Close(); err != nil {
+					t.Fatalf("Close() of frozen stack error = %v, want nil", err)
+				}
+				if _, err := s.Pop(); !errors.Is(err, ErrClosed) {
+					t.Errorf("Pop() after Close() error = %v, want ErrClosed", err)
+				}
+			})
+		})
+	}
+}
+
+func TestBlockingPush(t *testing.T) {
+	t.Run("pushes when room", func(t *testing.T) {
+		s := New[int](WithCapacity[int](1))
+
+		if err := s.BlockingPush(context.Background(), 1); err != nil {
+			t.Errorf("BlockingPush() error = %v, want nil", err)
+		}
+		if val, _ := s.Peek(); val != 1 {
+			t.Errorf("Peek() after BlockingPush() = %d, want 1", val)
+		}
+	})
+
+	t.Run("waits for pop", func(t *testing.T) {
+		s := New[int](WithCapacity[int](1), WithItems([]int{1}))
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_, _ = s.Pop()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := s.BlockingPush(ctx, 2); err != nil {
+			t.Errorf("BlockingPush() error = %v, want nil", err)
+		}
+		if val, _ := s.Peek(); val != 2 {
+			t.Errorf("Peek() after BlockingPush() = %d, want 2", val)
+		}
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		s := New[int](WithCapacity[int](1), WithItems([]int{1}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := s.BlockingPush(ctx, 2)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("BlockingPush() error = %v, want context.DeadlineExceeded", err)
+		}
+		if size := s.Size(); size != 1 {
+			t.Errorf("Size after cancelled BlockingPush() = %d, want 1", size)
+		}
+		if val, _ := s.Peek(); val != 1 {
+			t.Errorf("Peek() after cancelled BlockingPush() = %d, want 1", val)
+		}
+	})
+
+	t.Run("zero capacity", func(t *testing.T) {
+		s := New[int](WithCapacity[int](0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := s.BlockingPush(ctx, 1); !errors.Is(err, context.Canceled) {
+			t.Errorf("BlockingPush() on zero capacity stack error = %v, want context.Canceled", err)
+		}
+	})
+}
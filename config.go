@@ -1,5 +1,10 @@
 package stack
 
+import (
+	"fmt"
+	"time"
+)
+
 // Option represents a configuration function that can be applied to a stack during creation.
 // Options follow the functional options pattern for flexible and extensible configuration.
 type Option[T any] func(*stack[T])
@@ -27,12 +32,403 @@ const (
 //	s := stack.New[int](stack.WithCapacity[int](0))    // No items allowed
 //	s := stack.New[int](stack.WithCapacity[int](stack.UnlimitedCapacity)) // No limit
 //
-// Panics if cap < UnlimitedCapacity (i.e., cap < -1).
+// New panics if cap < UnlimitedCapacity (i.e., cap < -1); NewChecked returns an
+// error wrapping ErrInvalidCapacity instead.
 func WithCapacity[T any](cap int) Option[T] {
 	return func(s *stack[T]) {
 		if cap < UnlimitedCapacity {
-			panic("cannot specify arbitrary negative capacity")
+			s.reject(fmt.Errorf("%w: cannot specify arbitrary negative capacity %d", ErrInvalidCapacity, cap))
+			return
 		}
 		s.capacity = cap
 	}
 }
+
+// WithItems returns an option that seeds the stack with a copy of the provided items.
+//
+// Items are pushed in order, so items[0] ends up at the bottom of the stack and
+// items[len(items)-1] on top. The slice is copied; later changes to it do not
+// affect the stack.
+//
+// The seeded items are checked against the capacity after all options have been
+// applied, so WithItems may appear before or after WithCapacity.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{1, 2, 3}))
+//	val, _ := s.Pop() // returns 3
+//
+// Panics during New if the number of items exceeds the configured capacity.
+func WithItems[T any](items []T) Option[T] {
+	return func(s *stack[T]) {
+		s.items = make([]T, len(items))
+		copy(s.items, items)
+	}
+}
+
+// WithInitialCapacity returns an option that pre-allocates storage for n items
+// when the stack is created, avoiding reallocations as it fills. Unlike
+// WithCapacity it does not limit the number of items; it is a performance hint
+// only. For bounded stacks no more than the capacity is allocated.
+//
+// Example:
+//
+//	// Expect around 1000 items, but allow more
+//	s := stack.New[int](stack.WithInitialCapacity[int](1000))
+//
+// New panics if n is negative; NewChecked returns an error wrapping
+// ErrInvalidCapacity instead.
+func WithInitialCapacity[T any](n int) Option[T] {
+	return func(s *stack[T]) {
+		if n < 0 {
+			s.reject(fmt.Errorf("%w: cannot pre-allocate %d items", ErrInvalidCapacity, n))
+			return
+		}
+		s.initialCapacity = n
+	}
+}
+
+// WithFixedArray returns an option that backs a bounded stack with storage for
+// exactly its capacity, allocated once when the stack is created. The storage
+// is an ordinary slice sized up front rather than a separate array type, so
+// Push and Pop reslice it as usual but never allocate, and ShrinkToFit leaves
+// it in place. Changing the capacity reallocates the storage to the new
+// capacity, unless the new capacity is unlimited, in which case the stack
+// grows as usual from then on.
+//
+// Storage is only allocated up front with this option; other bounded stacks
+// grow their storage as items are pushed, however small their capacity.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithCapacity[int](1024), stack.WithFixedArray[int]())
+//
+// New panics if the stack has unlimited capacity; NewChecked returns an error
+// wrapping ErrInvalidCapacity instead.
+func WithFixedArray[T any]() Option[T] {
+	return func(s *stack[T]) {
+		s.fixed = true
+	}
+}
+
+// WithMaxBytes returns an option that bounds the stack by the total size of its
+// items rather than, or in addition to, their number. The size of each item is
+// reported by sizeOf, which must return the same value for an item every time
+// it is called, and the running total is kept up to date as items are pushed
+// and popped.
+//
+// A push that would take the total above maxBytes returns ErrOverflow, or, with
+// OverflowDropOldest, evicts the oldest items until the new item fits. An item
+// larger than maxBytes on its own can never be pushed. IsFull and Capacity only
+// consider the number of items.
+//
+// Example:
+//
+//	// Buffer at most 1 MiB of payloads
+//	s := stack.New[[]byte](stack.WithMaxBytes(1<<20, func(b []byte) int { return len(b) }))
+//
+// New panics if maxBytes is negative or sizeOf is nil; NewChecked returns an
+// error wrapping ErrInvalidOption instead. Stacks bounded by bytes cannot be
+// sharded.
+func WithMaxBytes[T any](maxBytes int, sizeOf func(T) int) Option[T] {
+	return func(s *stack[T]) {
+		if maxBytes < 0 {
+			s.reject(fmt.Errorf("%w: cannot specify a negative byte limit %d", ErrInvalidOption, maxBytes))
+			return
+		}
+		if sizeOf == nil {
+			s.reject(fmt.Errorf("%w: cannot bound by bytes without a size function", ErrInvalidOption))
+			return
+		}
+		s.maxBytes = maxBytes
+		s.sizeOf = sizeOf
+	}
+}
+
+// WithThreadSafety returns an option that controls whether the stack synchronizes access.
+//
+// Stacks are thread-safe by default. Passing false removes all locking overhead,
+// which is useful in hot loops confined to a single goroutine.
+//
+// WARNING: a stack created with WithThreadSafety(false) must never be shared
+// across goroutines. Concurrent use results in data races and corrupted state.
+// The blocking operations (BlockingPop, BlockingPush) can only be woken by
+// another goroutine and are therefore not usable on such a stack.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithThreadSafety[int](false)) // Single goroutine only
+func WithThreadSafety[T any](enabled bool) Option[T] {
+	return func(s *stack[T]) {
+		s.unsynced = !enabled
+	}
+}
+
+// OverflowPolicy determines what happens when an item is pushed onto a full stack.
+type OverflowPolicy int
+
+const (
+	// OverflowReject rejects the push with ErrOverflow, leaving the stack unchanged.
+	// This is the default policy.
+	OverflowReject OverflowPolicy = iota
+
+	// OverflowDropOldest evicts the oldest (bottom) item to make room for the new one,
+	// so pushes never fail on a stack with positive capacity. Evicting the bottom item
+	// shifts every remaining item down, which costs O(n) per eviction.
+	OverflowDropOldest
+)
+
+// WithOverflowPolicy returns an option that sets how the stack handles pushes when full.
+//
+// The policy applies to every operation that adds items, including PushMany, TryPush,
+// BlockingPush and Dup. A stack with zero capacity cannot hold any items and rejects
+// all pushes regardless of the policy.
+//
+// Example:
+//
+//	// Keep only the 100 most recent events
+//	s := stack.New[Event](
+//		stack.WithCapacity[Event](100),
+//		stack.WithOverflowPolicy[Event](stack.OverflowDropOldest),
+//	)
+func WithOverflowPolicy[T any](policy OverflowPolicy) Option[T] {
+	return func(s *stack[T]) {
+		s.policy = policy
+	}
+}
+
+// WithEvictionHandler returns an option that registers fn to be called for each item
+// dropped by the overflow policy, such as OverflowDropOldest. This lets evicted items
+// release resources they hold.
+//
+// The handler runs after the stack has been updated and after its lock has been
+// released, so the handler may safely call methods on the stack. Because the lock is
+// no longer held, other goroutines may modify the stack before or while the handler
+// runs. Items are passed to the handler from oldest to newest, on the goroutine that
+// performed the push.
+//
+// Example:
+//
+//	s := stack.New[*os.File](
+//		stack.WithCapacity[*os.File](10),
+//		stack.WithOverflowPolicy[*os.File](stack.OverflowDropOldest),
+//		stack.WithEvictionHandler(func(f *os.File) { f.Close() }),
+//	)
+func WithEvictionHandler[T any](fn func(evicted T)) Option[T] {
+	return func(s *stack[T]) {
+		s.onEvict = fn
+	}
+}
+
+// WithObserver returns an option that registers obs to be notified of every
+// successful push and pop, which is useful for instrumentation such as metrics.
+//
+// Notifications are delivered after the operation has completed and after the lock
+// has been released, so observers may perform I/O or call methods on the stack
+// without blocking other goroutines. Because the lock is no longer held, the stack
+// may already have changed again by the time an observer runs. Operations that
+// push or pop several items, such as PushMany, notify once per item, in order.
+//
+// Example:
+//
+//	s := stack.New[Job](stack.WithObserver[Job](metrics))
+func WithObserver[T any](obs Observer[T]) Option[T] {
+	return func(s *stack[T]) {
+		s.observer = obs
+	}
+}
+
+// WithEncoder returns an option that registers encode to convert items to bytes
+// when the stack is written with WriteTo. Stacks of strings or byte slices are
+// written as-is without an encoder.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithEncoder(func(n int) []byte {
+//		return strconv.AppendInt(nil, int64(n), 10)
+//	}))
+func WithEncoder[T any](encode func(T) []byte) Option[T] {
+	return func(s *stack[T]) {
+		s.encode = encode
+	}
+}
+
+// WithComparator returns an option that orders items with less, enabling Min and
+// Max on stacks of any type. The stack keeps a running minimum and maximum for
+// every item, so both are available in O(1) time at roughly three times the
+// memory of a plain stack.
+//
+// Example:
+//
+//	s := stack.New[Task](stack.WithComparator(func(a, b Task) bool {
+//		return a.Priority < b.Priority
+//	}))
+//	urgent, _ := s.Max() // Task with the highest priority
+func WithComparator[T any](less func(a, b T) bool) Option[T] {
+	return func(s *stack[T]) {
+		s.less = less
+	}
+}
+
+// WithSignalChannel returns an option that makes the stack send on ch after
+// every successful push, so that an existing select loop can wake up to pop.
+// Sends never block: if ch is not ready to receive, the signal is dropped, so
+// several pushes may be coalesced into a single signal. A buffered channel with
+// capacity 1 ensures that at least one signal is pending after any push.
+//
+// Unlike Notify, which only fires when the stack becomes non-empty, the
+// channel is signalled for every push, including each item of PushMany.
+//
+// Example:
+//
+//	wake := make(chan struct{}, 1)
+//	s := stack.New[Job](stack.WithSignalChannel[Job](wake))
+//	for {
+//		select {
+//		case <-wake:
+//			for job, ok := s.TryPop(); ok; job, ok = s.TryPop() {
+//				run(job)
+//			}
+//		case <-ctx.Done():
+//			return
+//		}
+//	}
+func WithSignalChannel[T any](ch chan<- struct{}) Option[T] {
+	return func(s *stack[T]) {
+		s.signal = ch
+	}
+}
+
+// WithGrowthPolicy returns an option that controls how much storage is
+// allocated when a push finds the stack's storage full, instead of relying on
+// append's built-in strategy. grow is called with the current storage size, in
+// items, and returns the new size; a result that is not larger than the current
+// size grows the storage by one item. Storage never grows beyond the capacity
+// of a bounded stack, so the policy matters most for unlimited stacks.
+//
+// Example:
+//
+//	// Grow by 25% at a time to limit over-allocation of a huge stack
+//	s := stack.New[Record](stack.WithGrowthPolicy[Record](func(n int) int {
+//		return n + n/4 + 16
+//	}))
+func WithGrowthPolicy[T any](grow func(current int) int) Option[T] {
+	return func(s *stack[T]) {
+		s.growth = grow
+	}
+}
+
+// WithName returns an option that labels the stack with name, which is
+// reported by Name, included in the output of String and recorded in
+// OverflowError and UnderflowError, so that logs can tell many stacks apart.
+//
+// Example:
+//
+//	s := stack.New[Job](stack.WithName[Job]("jobs"), stack.WithCapacity[Job](100))
+//	err := s.Push(job) // stack overflow in "jobs" (size 100, capacity 100)
+func WithName[T any](name string) Option[T] {
+	return func(s *stack[T]) {
+		s.name = name
+	}
+}
+
+// WithMetricsReporter returns an option that starts a background goroutine
+// calling report with the stack's Stats every interval, so that long-running
+// services can sample its depth without a ticker of their own. report runs
+// on that goroutine without holding any lock. The goroutine is started by the
+// constructor and stopped by Close, and keeps the stack reachable until then,
+// so the option only makes sense for a stack that will be closed. It is
+// ignored by NewPool.
+//
+// Example:
+//
+//	s := stack.New[Job](stack.WithMetricsReporter[Job](10*time.Second, func(st stack.Stats) {
+//		depthGauge.Set(float64(st.Pushes - st.Pops))
+//	}))
+//	defer s.Close()
+//
+// New panics if interval is not positive or report is nil; NewChecked
+// returns an error wrapping ErrInvalidOption instead.
+func WithMetricsReporter[T any](interval time.Duration, report func(Stats)) Option[T] {
+	return func(s *stack[T]) {
+		if interval <= 0 {
+			s.reject(fmt.Errorf("%w: cannot report metrics every %v", ErrInvalidOption, interval))
+			return
+		}
+		if report == nil {
+			s.reject(fmt.Errorf("%w: cannot report metrics without a report function", ErrInvalidOption))
+			return
+		}
+		s.reportEvery = interval
+		s.report = report
+	}
+}
+
+// WithBinaryCodec returns an option that registers encode and decode to
+// convert items to and from bytes when the stack is encoded with
+// MarshalBinary and UnmarshalBinary. Stacks of strings or byte slices are
+// encoded as-is without a codec. The slice passed to decode is only valid
+// until decode returns.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithBinaryCodec(
+//		func(n int) ([]byte, error) { return strconv.AppendInt(nil, int64(n), 10), nil },
+//		func(b []byte) (int, error) { return strconv.Atoi(string(b)) },
+//	))
+//
+// New panics if encode or decode is nil; NewChecked returns an error wrapping
+// ErrInvalidOption instead.
+func WithBinaryCodec[T any](encode func(T) ([]byte, error), decode func([]byte) (T, error)) Option[T] {
+	return func(s *stack[T]) {
+		if encode == nil || decode == nil {
+			s.reject(fmt.Errorf("%w: cannot register a binary codec without both functions", ErrInvalidOption))
+			return
+		}
+		s.encodeBinary = encode
+		s.decodeBinary = decode
+	}
+}
+
+// WithOnEmpty returns an option that registers fn to be called when a change,
+// such as Pop, Clear or DrainAll, takes the stack from holding items to being
+// empty, for example to release a resource once a buffer drains. fn is called
+// after the lock is released, so it may use the stack.
+//
+// The option cannot be used with NewSharded, which panics if it is given.
+//
+// Example:
+//
+//	jobs := stack.New[Job](stack.WithOnEmpty[Job](conn.Release))
+func WithOnEmpty[T any](fn func()) Option[T] {
+	return func(s *stack[T]) {
+		s.onEmpty = fn
+	}
+}
+
+// WithUniqueness returns an option that keeps at most one copy of each item on
+// the stack, tracked in a set that Pop, Clear and the other removals keep in
+// sync. Pushing an item already on the stack returns ErrDuplicate if reject is
+// true, or is a silent no-op that leaves the stack unchanged if it is false;
+// TryPush returns false either way. PushMany checks the whole batch,
+// including duplicates within it: with reject it pushes nothing, without it
+// pushes only the new items.
+//
+// Only pushes are checked. Transaction, UpdateTop and methods that replace
+// the contents wholesale, such as Restore and UnmarshalJSON, may introduce
+// duplicates, which stay tracked until they are removed.
+//
+// Example:
+//
+//	visited := stack.New[string](stack.WithUniqueness[string](false))
+//	visited.Push("a")
+//	visited.Push("a") // No-op, visited.Size() is still 1
+//
+// New panics if the seed items contain duplicates; NewChecked returns an
+// error wrapping ErrDuplicate instead. NewSharded panics if given this option.
+func WithUniqueness[T comparable](reject bool) Option[T] {
+	return func(s *stack[T]) {
+		s.unique = make(itemCounts[T])
+		s.rejectDuplicates = reject
+	}
+}
@@ -0,0 +1,154 @@
+package stack
+
+// mover is implemented by the stacks of this package so that DrainTo can move
+// items between stacks while holding all of their locks at once.
+type mover[T any] interface {
+	Stack[T]
+
+	// popLocked removes and returns the top item, or reports false if the
+	// stack is empty. Callers must hold the write locks.
+	popLocked() (T, bool)
+
+	// pushLocked pushes val, applying the overflow policy, and returns an
+	// *OverflowError if it does not fit. Callers must hold the write locks.
+	pushLocked(val T) error
+
+	// sealed returns ErrClosed or ErrFrozen if items may no longer be moved
+	// in or out of the stack.
+	sealed() error
+
+	// wake wakes goroutines blocked on the stack. Callers must not hold any locks.
+	wake()
+}
+
+func (s *stack[T]) DrainTo(dst Stack[T], n int) (int, error) {
+	return drainTo(s, dst, n)
+}
+
+func (s *sharded[T]) DrainTo(dst Stack[T], n int) (int, error) {
+	return drainTo(s, dst, n)
+}
+
+// drainTo moves up to n items, or every item if n is negative, from src to
+// dst. Both stacks are write-locked together, in a consistent order, so the
+// move is atomic with respect to other operations on either stack. If dst is
+// not implemented by this package, only src is locked and items are pushed
+// to dst with its Push method.
+func drainTo[T any](src mover[T], dst Stack[T], n int) (int, error) {
+	if Stack[T](src) == dst {
+		return 0, nil
+	}
+
+	push := dst.Push
+	locks, ok := lockOrder[T](src, dst)
+	if ok {
+		push = dst.(mover[T]).pushLocked
+	} else {
+		locks, _ = lockOrder[T](src)
+	}
+
+	unlock := lockStacks(locks)
+	defer func() {
+		unlock()
+		src.wake()
+		if ok {
+			dst.(mover[T]).wake()
+		}
+	}()
+
+	if err := src.sealed(); err != nil {
+		return 0, err
+	}
+	if ok {
+		if err := dst.(mover[T]).sealed(); err != nil {
+			return 0, err
+		}
+	}
+
+	moved := 0
+	for n < 0 || moved < n {
+		val, ok := src.popLocked()
+		if !ok {
+			break
+		}
+		if err := push(val); err != nil {
+			// src has just given up val, so there is always room to put it back.
+			_ = src.pushLocked(val)
+			return moved, err
+		}
+		moved++
+	}
+
+	return moved, nil
+}
+
+func (s *stack[T]) popLocked() (T, bool) {
+	if len(s.items) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	val := s.pop()
+	s.didPop(val)
+	s.broadcast()
+
+	return val, true
+}
+
+func (s *stack[T]) pushLocked(val T) error {
+	if dup, err := s.duplicate(val); dup {
+		return err
+	}
+	if !s.reserve(val) {
+		s.stats.overflows.Add(1)
+		return &OverflowError{Name: s.name, Capacity: s.capacity, Size: len(s.items)}
+	}
+
+	s.push(val)
+	s.didPush(val)
+	s.broadcast()
+
+	return nil
+}
+
+// wake is a no-op: a stack wakes its waiters through broadcast while locked.
+func (s *stack[T]) wake() {}
+
+func (s *sharded[T]) popLocked() (T, bool) {
+	start := s.start()
+	for i := range s.shards {
+		if val, ok := s.shards[(start+i)%len(s.shards)].popLocked(); ok {
+			return val, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (s *sharded[T]) pushLocked(val T) error {
+	// Prefer a shard with room; once every shard is full, the overflow
+	// policy may evict from any shard able to hold the item.
+	start := s.start()
+	for i := range s.shards {
+		if sh := s.shards[(start+i)%len(s.shards)]; sh.fits(val) {
+			return sh.pushLocked(val)
+		}
+	}
+	for i := range s.shards {
+		sh := s.shards[(start+i)%len(s.shards)]
+		if sh.reserve(val) {
+			sh.push(val)
+			sh.didPush(val)
+			sh.broadcast()
+			return nil
+		}
+	}
+
+	s.stats.overflows.Add(1)
+	return &OverflowError{Name: s.name, Capacity: s.capacity, Size: s.size()}
+}
+
+func (s *sharded[T]) wake() {
+	s.notify()
+}
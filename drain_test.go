@@ -0,0 +1,114 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestDrainTo(t *testing.T) {
+	t.Run("moves n items", func(t *testing.T) {
+		src := New[int](WithItems([]int{1, 2, 3, 4}))
+		dst := New[int](WithItems([]int{9}))
+
+		moved, err := src.DrainTo(dst, 2)
+		if err != nil || moved != 2 {
+			t.Fatalf("DrainTo(dst, 2) = %d, %v, want 2, nil", moved, err)
+		}
+		if got := src.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("source after DrainTo() = %v, want [1 2]", got)
+		}
+		if got := dst.ToSlice(); !slices.Equal(got, []int{9, 4, 3}) {
+			t.Errorf("destination after DrainTo() = %v, want [9 4 3]", got)
+		}
+	})
+
+	t.Run("negative n moves everything", func(t *testing.T) {
+		src := New[int](WithItems([]int{1, 2, 3}))
+		dst := New[int]()
+
+		if moved, err := src.DrainTo(dst, -1); err != nil || moved != 3 {
+			t.Fatalf("DrainTo(dst, -1) = %d, %v, want 3, nil", moved, err)
+		}
+		if !src.IsEmpty() {
+			t.Errorf("source Size() after DrainTo() = %d, want 0", src.Size())
+		}
+		if moved, err := src.DrainTo(dst, 5); err != nil || moved != 0 {
+			t.Errorf("DrainTo() from empty stack = %d, %v, want 0, nil", moved, err)
+		}
+	})
+
+	t.Run("stops on overflow", func(t *testing.T) {
+		src := New[int](WithItems([]int{1, 2, 3}))
+		dst := New[int](WithCapacity[int](2))
+
+		moved, err := src.DrainTo(dst, -1)
+		var overflow *OverflowError
+		if moved != 2 || !errors.As(err, &overflow) {
+			t.Fatalf("DrainTo() into small stack = %d, %v, want 2, *OverflowError", moved, err)
+		}
+		if got := src.ToSlice(); !slices.Equal(got, []int{1}) {
+			t.Errorf("source after overflow = %v, want [1]", got)
+		}
+		if stats := dst.Stats(); stats.Overflows != 1 {
+			t.Errorf("destination Stats().Overflows = %d, want 1", stats.Overflows)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		src := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+		dst := NewSharded[int](2, WithCapacity[int](3))
+
+		moved, err := src.DrainTo(dst, -1)
+		if moved != 3 || !errors.Is(err, ErrOverflow) {
+			t.Fatalf("DrainTo() = %d, %v, want 3, ErrOverflow", moved, err)
+		}
+		if src.Size() != 2 || dst.Size() != 3 {
+			t.Errorf("sizes after DrainTo() = %d, %d, want 2, 3", src.Size(), dst.Size())
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		src := New[int](WithItems([]int{1}))
+		dst := New[int]()
+		_ = dst.Close()
+
+		if _, err := src.DrainTo(dst, 1); !errors.Is(err, ErrClosed) {
+			t.Errorf("DrainTo() into closed stack error = %v, want ErrClosed", err)
+		}
+		if size := src.Size(); size != 1 {
+			t.Errorf("source Size() = %d, want 1", size)
+		}
+	})
+
+	t.Run("into itself", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2}))
+		if moved, err := s.DrainTo(s, -1); err != nil || moved != 0 {
+			t.Errorf("DrainTo(self) = %d, %v, want 0, nil", moved, err)
+		}
+	})
+
+	t.Run("opposite directions", func(t *testing.T) {
+		a := New[int](WithItems(make([]int, 100)))
+		b := New[int](WithItems(make([]int, 100)))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_, _ = a.DrainTo(b, 1)
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = b.DrainTo(a, 1)
+			}()
+		}
+		wg.Wait()
+
+		if total := a.Size() + b.Size(); total != 200 {
+			t.Errorf("total Size() after transfers = %d, want 200", total)
+		}
+	})
+}
@@ -0,0 +1,552 @@
+package stack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// gobStack is the wire representation of a stack used by GobEncode and GobDecode.
+type gobStack[T any] struct {
+	Capacity int
+	Items    []T
+}
+
+// MarshalJSON encodes the stack as a JSON array ordered from bottom to top.
+func (s *stack[T]) MarshalJSON() ([]byte, error) {
+	s.rlock()
+	defer s.runlock()
+
+	return json.Marshal(s.items)
+}
+
+// UnmarshalJSON replaces the contents of the stack with a JSON array ordered
+// from bottom to top. The configured capacity is preserved; if the array holds
+// more items than the capacity allows, ErrOverflow is returned and the stack
+// is left unchanged.
+func (s *stack[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	if items == nil {
+		items = make([]T, 0)
+	}
+
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if s.capacity >= 0 && len(items) > s.capacity {
+		return ErrOverflow
+	}
+	if s.sizeOf != nil && s.sizeSum(items) > s.maxBytes {
+		return ErrOverflow
+	}
+
+	s.items = items
+	s.fix()
+	s.reindex()
+	s.trackDepth()
+	s.broadcast()
+
+	return nil
+}
+
+// GobEncode encodes the capacity and items of the stack for use with encoding/gob.
+func (s *stack[T]) GobEncode() ([]byte, error) {
+	s.rlock()
+	defer s.runlock()
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(gobStack[T]{
+		Capacity: s.capacity,
+		Items:    s.items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the capacity and items of the stack with data produced by GobEncode.
+// A stack created with WithFixedArray keeps its own capacity instead, and
+// returns ErrOverflow if the items do not fit it.
+func (s *stack[T]) GobDecode(data []byte) error {
+	decoded, err := decodeGob[T](data)
+	if err != nil {
+		return err
+	}
+
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	capacity := s.decodedCapacity(decoded.Capacity)
+	if capacity >= 0 && len(decoded.Items) > capacity {
+		return ErrOverflow
+	}
+	if s.sizeOf != nil && s.sizeSum(decoded.Items) > s.maxBytes {
+		return ErrOverflow
+	}
+
+	s.capacity = capacity
+	s.items = decoded.Items
+	s.fix()
+	s.reindex()
+	s.trackDepth()
+	s.broadcast()
+
+	return nil
+}
+
+// decodeGob decodes and validates data produced by GobEncode.
+func decodeGob[T any](data []byte) (gobStack[T], error) {
+	var decoded gobStack[T]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return decoded, err
+	}
+	if decoded.Capacity < UnlimitedCapacity {
+		return decoded, errors.New("stack: invalid encoded capacity")
+	}
+	if decoded.Capacity >= 0 && len(decoded.Items) > decoded.Capacity {
+		return decoded, ErrOverflow
+	}
+	if decoded.Items == nil {
+		decoded.Items = make([]T, 0)
+	}
+
+	return decoded, nil
+}
+
+// MarshalJSON encodes the stack as a JSON array in the order listed by ToSlice.
+func (s *sharded[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToSlice())
+}
+
+// UnmarshalJSON replaces the contents of the stack with a JSON array, spreading
+// the items evenly across the shards. As for a plain stack, the configured
+// capacity is preserved, and ErrOverflow is returned with the stack left
+// unchanged if the array holds more items than it allows.
+func (s *sharded[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	s.lockAll()
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+	if s.capacity >= 0 && len(items) > s.capacity {
+		s.unlockAll()
+		return ErrOverflow
+	}
+
+	s.scatter(items)
+	s.unlockAll()
+	s.notify()
+
+	return nil
+}
+
+// GobEncode encodes the capacity and items of the stack for use with
+// encoding/gob, in the same format as a plain stack.
+func (s *sharded[T]) GobEncode() ([]byte, error) {
+	s.rlockAll()
+	encoded := gobStack[T]{Capacity: s.capacity, Items: s.gather()}
+	s.runlockAll()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(encoded); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the capacity and items of the stack with data produced by
+// GobEncode, spreading the items evenly across the shards. A stack created
+// with WithFixedArray keeps its own capacity instead, and returns ErrOverflow
+// if the items do not fit it.
+func (s *sharded[T]) GobDecode(data []byte) error {
+	decoded, err := decodeGob[T](data)
+	if err != nil {
+		return err
+	}
+
+	s.lockAll()
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+	capacity := s.decodedCapacity(decoded.Capacity)
+	if capacity >= 0 && len(decoded.Items) > capacity {
+		s.unlockAll()
+		return ErrOverflow
+	}
+
+	s.setCapacity(capacity)
+	s.scatter(decoded.Items)
+	s.unlockAll()
+	s.notify()
+
+	return nil
+}
+
+// decodedCapacity is decodedCapacity of a plain stack for a sharded stack,
+// whose shards are all configured alike. Callers must hold every shard's
+// write lock.
+func (s *sharded[T]) decodedCapacity(capacity int) int {
+	if s.shards[0].fixed {
+		return s.capacity
+	}
+
+	return capacity
+}
+
+// binaryMagic and binaryVersion begin the output of MarshalBinary. Decoders
+// accept any version from 1 up to binaryVersion, so the format can be extended
+// without breaking data that is already stored.
+const (
+	binaryMagic   = "GSTK"
+	binaryVersion = 1
+)
+
+// errMalformedBinary is returned by UnmarshalBinary for data that was not
+// produced by MarshalBinary or has been truncated or extended.
+var errMalformedBinary = errors.New("stack: malformed binary data")
+
+// MarshalBinary encodes the capacity and items of the stack in a compact,
+// versioned format: a header holding a magic string, the format version, the
+// capacity and the number of items, followed by each item, bottom to top,
+// prefixed with its length. Items are converted with the codec registered
+// with WithBinaryCodec. Returns ErrNoEncoder if there is none and the items
+// are neither strings nor byte slices.
+func (s *stack[T]) MarshalBinary() ([]byte, error) {
+	encode, _ := s.binaryCodec()
+	if encode == nil {
+		return nil, ErrNoEncoder
+	}
+
+	s.rlock()
+	defer s.runlock()
+
+	return appendBinary(nil, s.capacity, s.items, encode)
+}
+
+// UnmarshalBinary replaces the capacity and items of the stack with data
+// produced by MarshalBinary, converting items with the codec registered with
+// WithBinaryCodec. A stack created with WithFixedArray keeps its own capacity
+// instead, and returns ErrOverflow if the items do not fit it. The stack is
+// left unchanged if data is malformed, was written by a newer version of the
+// format, or fails to decode.
+func (s *stack[T]) UnmarshalBinary(data []byte) error {
+	_, decode := s.binaryCodec()
+	if decode == nil {
+		return ErrNoEncoder
+	}
+	capacity, items, err := decodeBinary(data, decode)
+	if err != nil {
+		return err
+	}
+
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	adopted := s.decodedCapacity(capacity)
+	if adopted >= 0 && len(items) > adopted {
+		return ErrOverflow
+	}
+	if s.sizeOf != nil && s.sizeSum(items) > s.maxBytes {
+		return ErrOverflow
+	}
+
+	s.capacity = adopted
+	s.items = items
+	s.fix()
+	s.reindex()
+	s.trackDepth()
+	s.broadcast()
+
+	return nil
+}
+
+// appendBinary appends the MarshalBinary encoding of capacity and items to buf.
+func appendBinary[T any](buf []byte, capacity int, items []T, encode func(T) ([]byte, error)) ([]byte, error) {
+	buf = append(append(buf, binaryMagic...), binaryVersion)
+	buf = binary.AppendVarint(buf, int64(capacity))
+	buf = binary.AppendUvarint(buf, uint64(len(items)))
+	for _, item := range items {
+		b, err := encode(item)
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(b)))
+		buf = append(buf, b...)
+	}
+
+	return buf, nil
+}
+
+// decodeBinary decodes and validates data produced by MarshalBinary.
+func decodeBinary[T any](data []byte, decode func([]byte) (T, error)) (int, []T, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(binaryMagic))
+	if !ok || len(rest) == 0 {
+		return 0, nil, errMalformedBinary
+	}
+	if version := rest[0]; version == 0 || version > binaryVersion {
+		return 0, nil, fmt.Errorf("stack: unsupported binary version %d", version)
+	}
+	rest = rest[1:]
+
+	capacity, n := binary.Varint(rest)
+	if n <= 0 || capacity < UnlimitedCapacity || capacity > math.MaxInt {
+		return 0, nil, errMalformedBinary
+	}
+	rest = rest[n:]
+	count, n := binary.Uvarint(rest)
+	if n <= 0 || count > uint64(len(rest)-n) {
+		// Every item takes at least one byte for its length.
+		return 0, nil, errMalformedBinary
+	}
+	rest = rest[n:]
+	if capacity >= 0 && count > uint64(capacity) {
+		return 0, nil, ErrOverflow
+	}
+
+	items := make([]T, 0, count)
+	for range count {
+		size, n := binary.Uvarint(rest)
+		if n <= 0 || size > uint64(len(rest)-n) {
+			return 0, nil, errMalformedBinary
+		}
+		item, err := decode(rest[n : n+int(size)])
+		if err != nil {
+			return 0, nil, err
+		}
+		items = append(items, item)
+		rest = rest[n+int(size):]
+	}
+	if len(rest) != 0 {
+		return 0, nil, errMalformedBinary
+	}
+
+	return int(capacity), items, nil
+}
+
+// MarshalBinary encodes the capacity and items of the stack in the same format
+// as a plain stack, using the codec of its shards.
+func (s *sharded[T]) MarshalBinary() ([]byte, error) {
+	encode, _ := s.shards[0].binaryCodec()
+	if encode == nil {
+		return nil, ErrNoEncoder
+	}
+
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return appendBinary(nil, s.capacity, s.gather(), encode)
+}
+
+// UnmarshalBinary replaces the capacity and items of the stack with data
+// produced by MarshalBinary, spreading the items evenly across the shards. As
+// for GobDecode, a stack created with WithFixedArray keeps its own capacity.
+func (s *sharded[T]) UnmarshalBinary(data []byte) error {
+	_, decode := s.shards[0].binaryCodec()
+	if decode == nil {
+		return ErrNoEncoder
+	}
+	capacity, items, err := decodeBinary(data, decode)
+	if err != nil {
+		return err
+	}
+
+	s.lockAll()
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+	capacity = s.decodedCapacity(capacity)
+	if capacity >= 0 && len(items) > capacity {
+		s.unlockAll()
+		return ErrOverflow
+	}
+
+	s.setCapacity(capacity)
+	s.scatter(items)
+	s.unlockAll()
+	s.notify()
+
+	return nil
+}
+
+// decodedCapacity returns the capacity to adopt when decoding data that
+// stores capacity. The stored capacity is untrusted input, and a stack created
+// with WithFixedArray would allocate storage for all of it, so such stacks keep
+// their own capacity. Other stacks adopt it, since their storage only grows as
+// items are pushed. Callers must hold the write lock.
+func (s *stack[T]) decodedCapacity(capacity int) int {
+	if s.fixed {
+		return s.capacity
+	}
+
+	return capacity
+}
+
+// binaryCodec returns the codec registered with WithBinaryCodec, falling back
+// to storing strings and byte slices as-is. Both functions are nil if the
+// stack has no codec and its items are of any other type.
+func (s *stack[T]) binaryCodec() (func(T) ([]byte, error), func([]byte) (T, error)) {
+	if s.encodeBinary != nil {
+		return s.encodeBinary, s.decodeBinary
+	}
+
+	var zero T
+	switch any(zero).(type) {
+	case string:
+		return func(val T) ([]byte, error) { return []byte(any(val).(string)), nil },
+			func(b []byte) (T, error) { return any(string(b)).(T), nil }
+	case []byte:
+		return func(val T) ([]byte, error) { return any(val).([]byte), nil },
+			func(b []byte) (T, error) { return any(bytes.Clone(b)).(T), nil }
+	default:
+		return nil, nil
+	}
+}
+
+// WriteTo implements io.WriterTo, writing each item from bottom to top using
+// the encoder registered with WithEncoder.
+func (s *stack[T]) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(s, w, s.encode)
+}
+
+func (s *sharded[T]) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(s, w, s.shards[0].encode)
+}
+
+// writeTo writes s to w with encode, falling back to writing strings and
+// byte slices as-is when encode is nil.
+func writeTo[T any](s Stack[T], w io.Writer, encode func(T) []byte) (int64, error) {
+	if encode == nil {
+		var zero T
+		switch any(zero).(type) {
+		case string:
+			encode = func(val T) []byte { return []byte(any(val).(string)) }
+		case []byte:
+			encode = func(val T) []byte { return any(val).([]byte) }
+		default:
+			return 0, ErrNoEncoder
+		}
+	}
+
+	return WriteToFunc(s, w, encode)
+}
+
+// WriteToFunc writes each item of s to w from bottom to top, using encode to
+// convert items to bytes. Each item is written as a record: its length as a
+// uvarint, followed by its bytes, so that ReadFromFunc can read the items back
+// whatever bytes they contain. It returns the total number of bytes written
+// and stops at the first error returned by w.
+//
+// The items are written from a snapshot, so the stack is not locked while w
+// is being written to.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{1, 2, 3}))
+//	stack.WriteToFunc(s, f, func(n int) []byte {
+//		return strconv.AppendInt(nil, int64(n), 10)
+//	})
+func WriteToFunc[T any](s Stack[T], w io.Writer, encode func(T) []byte) (int64, error) {
+	var total int64
+	var record []byte
+	for _, item := range s.ToSlice() {
+		b := encode(item)
+		record = binary.AppendUvarint(record[:0], uint64(len(b)))
+		record = append(record, b...)
+
+		n, err := w.Write(record)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// ReadFromFunc creates a stack from the records read from r, as written by
+// WriteTo and WriteToFunc. Each record is passed to decode and the result is
+// pushed, so the first record ends up at the bottom of the stack. Reading stops
+// at the end of r or when decode reports false.
+//
+// The slice passed to decode is only valid until decode returns; decoders that
+// retain it, such as for a stack of byte slices, must copy it.
+//
+// Options are applied as for New. Returns ErrOverflow if r holds more records
+// than the stack can hold, io.ErrUnexpectedEOF if r ends within a record, or
+// the first error returned by r.
+//
+// Example:
+//
+//	s, err := stack.ReadFromFunc(f, func(b []byte) (int, bool) {
+//		n, err := strconv.Atoi(string(b))
+//		return n, err == nil
+//	})
+func ReadFromFunc[T any](r io.Reader, decode func([]byte) (T, bool), opts ...Option[T]) (Stack[T], error) {
+	// The metrics reporter is started only once the stack is returned, so
+	// that failing to read it leaks no goroutine.
+	s := newStack(opts...)
+
+	br := bufio.NewReader(r)
+	var record bytes.Buffer
+	for {
+		size, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if size > math.MaxInt64 {
+			return nil, errMalformedBinary
+		}
+
+		// Copy rather than allocate size bytes up front, so that a corrupt
+		// length cannot allocate more than r actually holds.
+		record.Reset()
+		if _, err := io.CopyN(&record, br, int64(size)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		val, ok := decode(record.Bytes())
+		if !ok {
+			break
+		}
+		if err := s.Push(val); err != nil {
+			return nil, err
+		}
+	}
+	s.startReporting()
+
+	return s, nil
+}
@@ -0,0 +1,565 @@
+package stack
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		s := New[int]()
+		_ = s.PushMany(1, 2, 3)
+
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v, want nil", err)
+		}
+		if string(data) != "[1,2,3]" {
+			t.Errorf("json.Marshal() = %s, want [1,2,3]", data)
+		}
+
+		decoded := New[int]()
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v, want nil", err)
+		}
+		if size := decoded.Size(); size != 3 {
+			t.Errorf("Size after json.Unmarshal() = %d, want 3", size)
+		}
+		if val, _ := decoded.Pop(); val != 3 {
+			t.Errorf("Pop() after json.Unmarshal() = %d, want 3", val)
+		}
+	})
+
+	t.Run("empty stack", func(t *testing.T) {
+		data, err := json.Marshal(New[string]())
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v, want nil", err)
+		}
+		if string(data) != "[]" {
+			t.Errorf("json.Marshal() = %s, want []", data)
+		}
+	})
+
+	t.Run("null", func(t *testing.T) {
+		s := New[int](WithItems([]int{1}))
+		if err := json.Unmarshal([]byte("null"), s); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v, want nil", err)
+		}
+		if !s.IsEmpty() {
+			t.Errorf("Size after json.Unmarshal(null) = %d, want 0", s.Size())
+		}
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		s := New[int](WithCapacity[int](2), WithItems([]int{9}))
+		err := json.Unmarshal([]byte("[1,2,3]"), s)
+		if !errors.Is(err, ErrOverflow) {
+			t.Errorf("json.Unmarshal() exceeding capacity error = %v, want ErrOverflow", err)
+		}
+		if val, _ := s.Peek(); val != 9 {
+			t.Errorf("Peek() after rejected json.Unmarshal() = %d, want 9", val)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		s := New[int]()
+		if err := json.Unmarshal([]byte(`{"a":1}`), s); err == nil {
+			t.Error("json.Unmarshal() of object error = nil, want non-nil")
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		s := NewSharded[int](4, WithItems([]int{1, 2, 3}))
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v, want nil", err)
+		}
+		if want, _ := json.Marshal(s.ToSlice()); string(data) != string(want) {
+			t.Errorf("json.Marshal() = %s, want %s", data, want)
+		}
+
+		decoded := NewSharded[int](2, WithCapacity[int](3))
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v, want nil", err)
+		}
+		if got := decoded.ToSlice(); !slices.Equal(got, s.ToSlice()) {
+			t.Errorf("ToSlice() after json.Unmarshal() = %v, want %v", got, s.ToSlice())
+		}
+		if err := json.Unmarshal([]byte("[1,2,3,4]"), decoded); !errors.Is(err, ErrOverflow) {
+			t.Errorf("json.Unmarshal() exceeding capacity error = %v, want ErrOverflow", err)
+		}
+	})
+}
+
+func TestGob(t *testing.T) {
+	roundTrip := func(t *testing.T, src Stack[int]) Stack[int] {
+		t.Helper()
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(src); err != nil {
+			t.Fatalf("Encode() error = %v, want nil", err)
+		}
+
+		dst := New[int]()
+		if err := gob.NewDecoder(&buf).Decode(dst); err != nil {
+			t.Fatalf("Decode() error = %v, want nil", err)
+		}
+
+		return dst
+	}
+
+	t.Run("empty stack", func(t *testing.T) {
+		dst := roundTrip(t, New[int]())
+		if !dst.IsEmpty() {
+			t.Errorf("Size after round trip = %d, want 0", dst.Size())
+		}
+		if got := dst.Capacity(); got != UnlimitedCapacity {
+			t.Errorf("Capacity after round trip = %d, want %d", got, UnlimitedCapacity)
+		}
+	})
+
+	t.Run("capacity-limited stack", func(t *testing.T) {
+		dst := roundTrip(t, New[int](WithCapacity[int](5), WithItems([]int{1, 2, 3})))
+		if got := dst.Capacity(); got != 5 {
+			t.Errorf("Capacity after round trip = %d, want 5", got)
+		}
+		if got := dst.Size(); got != 3 {
+			t.Errorf("Size after round trip = %d, want 3", got)
+		}
+		if val, _ := dst.Pop(); val != 3 {
+			t.Errorf("Pop() after round trip = %d, want 3", val)
+		}
+		if err := dst.PushMany(3, 4, 5, 6); !errors.Is(err, ErrOverflow) {
+			t.Errorf("PushMany() beyond restored capacity error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("struct field", func(t *testing.T) {
+		type wrapper struct {
+			S *stack[string]
+		}
+
+		var buf bytes.Buffer
+		src := wrapper{S: newStack[string](WithItems([]string{"a", "b"}))}
+		if err := gob.NewEncoder(&buf).Encode(src); err != nil {
+			t.Fatalf("Encode() error = %v, want nil", err)
+		}
+
+		var dst wrapper
+		if err := gob.NewDecoder(&buf).Decode(&dst); err != nil {
+			t.Fatalf("Decode() error = %v, want nil", err)
+		}
+		if val, _ := dst.S.Pop(); val != "b" {
+			t.Errorf("Pop() after round trip = %q, want %q", val, "b")
+		}
+		if err := dst.S.Push("c"); err != nil {
+			t.Errorf("Push() after round trip error = %v, want nil", err)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		src := NewSharded[int](3, WithCapacity[int](6), WithItems([]int{1, 2, 3, 4}))
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(src); err != nil {
+			t.Fatalf("Encode() error = %v, want nil", err)
+		}
+		data := slices.Clone(buf.Bytes())
+
+		dst := NewSharded[int](2)
+		if err := gob.NewDecoder(&buf).Decode(dst); err != nil {
+			t.Fatalf("Decode() error = %v, want nil", err)
+		}
+		if got := dst.ToSlice(); !slices.Equal(got, src.ToSlice()) {
+			t.Errorf("ToSlice() after round trip = %v, want %v", got, src.ToSlice())
+		}
+		if got := dst.Capacity(); got != 6 {
+			t.Errorf("Capacity() after round trip = %d, want 6", got)
+		}
+
+		plain := New[int]()
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(plain); err != nil {
+			t.Fatalf("Decode() into plain stack error = %v, want nil", err)
+		}
+		if got := plain.ToSlice(); !slices.Equal(got, src.ToSlice()) {
+			t.Errorf("ToSlice() of plain stack = %v, want %v", got, src.ToSlice())
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		s := New[int]()
+		if err := s.(*stack[int]).GobDecode([]byte("garbage")); err == nil {
+			t.Error("GobDecode() of garbage error = nil, want non-nil")
+		}
+	})
+
+	t.Run("hostile capacity", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(gobStack[int]{Capacity: 1 << 40}); err != nil {
+			t.Fatalf("Encode() error = %v, want nil", err)
+		}
+		data := buf.Bytes()
+
+		small := newStack(WithCapacity[int](10))
+		if err := small.GobDecode(data); err != nil {
+			t.Fatalf("GobDecode() error = %v, want nil", err)
+		}
+		if got := cap(small.items); got != 0 {
+			t.Errorf("storage after GobDecode() = %d, want 0", got)
+		}
+
+		fixed := newStack(WithCapacity[int](100), WithFixedArray[int]())
+		if err := fixed.GobDecode(data); err != nil {
+			t.Fatalf("GobDecode() into fixed array error = %v, want nil", err)
+		}
+		if got := fixed.Capacity(); got != 100 {
+			t.Errorf("Capacity() of fixed array after GobDecode() = %d, want 100", got)
+		}
+	})
+}
+
+func TestBinary(t *testing.T) {
+	codec := WithBinaryCodec(
+		func(n int) ([]byte, error) { return strconv.AppendInt(nil, int64(n), 10), nil },
+		func(b []byte) (int, error) { return strconv.Atoi(string(b)) },
+	)
+
+	t.Run("round trip", func(t *testing.T) {
+		src := newStack(codec, WithCapacity[int](5), WithItems([]int{1, 20, 300}))
+		data, err := src.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() error = %v, want nil", err)
+		}
+
+		dst := newStack(codec)
+		if err := dst.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() error = %v, want nil", err)
+		}
+		if got := dst.ToSlice(); !slices.Equal(got, []int{1, 20, 300}) {
+			t.Errorf("ToSlice() after round trip = %v, want [1 20 300]", got)
+		}
+		if got := dst.Capacity(); got != 5 {
+			t.Errorf("Capacity() after round trip = %d, want 5", got)
+		}
+	})
+
+	t.Run("strings without codec", func(t *testing.T) {
+		data, err := newStack(WithItems([]string{"a", "", "bc"})).MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() error = %v, want nil", err)
+		}
+
+		dst := newStack[string]()
+		if err := dst.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() error = %v, want nil", err)
+		}
+		if got := dst.ToSlice(); !slices.Equal(got, []string{"a", "", "bc"}) {
+			t.Errorf("ToSlice() after round trip = %q, want [a  bc]", got)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		src := NewSharded(3, codec, WithCapacity[int](6), WithItems([]int{1, 2, 3, 4}))
+		data, err := src.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() error = %v, want nil", err)
+		}
+
+		dst := NewSharded(2, codec)
+		if err := dst.(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() error = %v, want nil", err)
+		}
+		if got := dst.ToSlice(); !slices.Equal(got, src.ToSlice()) {
+			t.Errorf("ToSlice() after round trip = %v, want %v", got, src.ToSlice())
+		}
+		if got := dst.Capacity(); got != 6 {
+			t.Errorf("Capacity() after round trip = %d, want 6", got)
+		}
+
+		plain := newStack(codec)
+		if err := plain.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() into plain stack error = %v, want nil", err)
+		}
+		if got := plain.ToSlice(); !slices.Equal(got, src.ToSlice()) {
+			t.Errorf("ToSlice() of plain stack = %v, want %v", got, src.ToSlice())
+		}
+
+		small := NewSharded(2, codec, WithCapacity[int](2), WithFixedArray[int]())
+		if err := small.(encoding.BinaryUnmarshaler).UnmarshalBinary(data); !errors.Is(err, ErrOverflow) {
+			t.Errorf("UnmarshalBinary() of 4 items into fixed array of 2 error = %v, want ErrOverflow", err)
+		}
+		if _, err := NewSharded[int](2).(encoding.BinaryMarshaler).MarshalBinary(); !errors.Is(err, ErrNoEncoder) {
+			t.Errorf("MarshalBinary() without codec error = %v, want ErrNoEncoder", err)
+		}
+	})
+
+	t.Run("no codec", func(t *testing.T) {
+		s := newStack(WithItems([]int{1}))
+		if _, err := s.MarshalBinary(); !errors.Is(err, ErrNoEncoder) {
+			t.Errorf("MarshalBinary() error = %v, want ErrNoEncoder", err)
+		}
+		if err := s.UnmarshalBinary(nil); !errors.Is(err, ErrNoEncoder) {
+			t.Errorf("UnmarshalBinary() error = %v, want ErrNoEncoder", err)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		data, _ := newStack(codec, WithItems([]int{1, 2})).MarshalBinary()
+		newer := slices.Clone(data)
+		newer[len(binaryMagic)] = binaryVersion + 1
+		unversioned := slices.Clone(data)
+		unversioned[len(binaryMagic)] = 0
+
+		for name, input := range map[string][]byte{
+			"garbage":     []byte("garbage"),
+			"truncated":   data[:len(data)-1],
+			"trailing":    append(slices.Clone(data), 0),
+			"newer":       newer,
+			"unversioned": unversioned,
+		} {
+			dst := newStack(codec, WithItems([]int{9}))
+			if err := dst.UnmarshalBinary(input); err == nil {
+				t.Errorf("UnmarshalBinary() of %s data error = nil, want non-nil", name)
+			}
+			if got := dst.ToSlice(); !slices.Equal(got, []int{9}) {
+				t.Errorf("ToSlice() after %s data = %v, want [9]", name, got)
+			}
+		}
+	})
+
+	t.Run("hostile capacity", func(t *testing.T) {
+		data := append([]byte(binaryMagic), binaryVersion)
+		data = binary.AppendVarint(data, 1<<40)
+		data = binary.AppendUvarint(data, 0)
+
+		small := newStack(WithCapacity[string](10))
+		if err := small.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() error = %v, want nil", err)
+		}
+		if got := cap(small.items); got != 0 {
+			t.Errorf("storage after UnmarshalBinary() = %d, want 0", got)
+		}
+
+		fixed := newStack(WithCapacity[string](100), WithFixedArray[string]())
+		if err := fixed.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() into fixed array error = %v, want nil", err)
+		}
+		if got := fixed.Capacity(); got != 100 {
+			t.Errorf("Capacity() of fixed array after UnmarshalBinary() = %d, want 100", got)
+		}
+
+		data, _ = newStack(WithItems([]string{"a", "b", "c"})).MarshalBinary()
+		tight := newStack(WithCapacity[string](2), WithFixedArray[string]())
+		if err := tight.UnmarshalBinary(data); !errors.Is(err, ErrOverflow) {
+			t.Errorf("UnmarshalBinary() of 3 items into fixed array of 2 error = %v, want ErrOverflow", err)
+		}
+	})
+}
+
+// failingWriter accepts limit bytes and then fails.
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errIO
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+var errIO = errors.New("i/o failed")
+
+// records returns the output of WriteTo for a stack holding items, from
+// bottom to top.
+func records(items ...string) *bytes.Buffer {
+	var buf bytes.Buffer
+	_, _ = New(WithItems(items)).WriteTo(&buf)
+	return &buf
+}
+
+func TestWriteTo(t *testing.T) {
+	t.Run("strings", func(t *testing.T) {
+		s := New(WithItems([]string{"a", "bc"}))
+		var buf bytes.Buffer
+
+		n, err := s.WriteTo(&buf)
+		if err != nil || n != 5 {
+			t.Fatalf("expected 5 bytes and no error, got %d, %v", n, err)
+		}
+		if buf.String() != "\x01a\x02bc" {
+			t.Errorf("expected bottom-to-top records, got %q", buf.String())
+		}
+	})
+
+	t.Run("byte slices", func(t *testing.T) {
+		s := NewSharded(2, WithItems([][]byte{[]byte("x"), []byte("yz")}))
+		var buf bytes.Buffer
+
+		if _, err := s.WriteTo(&buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if buf.String() != "\x01x\x02yz" {
+			t.Errorf("expected %q, got %q", "\x01x\x02yz", buf.String())
+		}
+	})
+
+	t.Run("registered encoder", func(t *testing.T) {
+		s := New(WithItems([]int{1, 22}), WithEncoder(func(n int) []byte {
+			return strconv.AppendInt(nil, int64(n), 10)
+		}))
+		var buf bytes.Buffer
+
+		if _, err := s.WriteTo(&buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if buf.String() != "\x011\x0222" {
+			t.Errorf("expected %q, got %q", "\x011\x0222", buf.String())
+		}
+	})
+
+	t.Run("no encoder", func(t *testing.T) {
+		s := New(WithItems([]int{1}))
+		var buf bytes.Buffer
+
+		if _, err := s.WriteTo(&buf); !errors.Is(err, ErrNoEncoder) {
+			t.Errorf("expected ErrNoEncoder, got %v", err)
+		}
+	})
+
+	t.Run("io.WriterTo", func(t *testing.T) {
+		var _ io.WriterTo = New[string]()
+	})
+}
+
+func TestWriteToFunc(t *testing.T) {
+	s := New(WithItems([]int{1, 22, 333}))
+	encode := func(n int) []byte { return strconv.AppendInt(nil, int64(n), 10) }
+
+	t.Run("writes all items", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		n, err := WriteToFunc(s, &buf, encode)
+		if err != nil || n != 9 {
+			t.Fatalf("expected 9 bytes and no error, got %d, %v", n, err)
+		}
+		if buf.String() != "\x011\x0222\x03333" {
+			t.Errorf("unexpected output %q", buf.String())
+		}
+	})
+
+	t.Run("writer error", func(t *testing.T) {
+		n, err := WriteToFunc(s, &failingWriter{limit: 4}, encode)
+		if !errors.Is(err, errIO) {
+			t.Errorf("expected writer error, got %v", err)
+		}
+		if n != 4 {
+			t.Errorf("expected 4 bytes written before the error, got %d", n)
+		}
+		if s.Size() != 3 {
+			t.Errorf("expected stack to be unchanged, got size %d", s.Size())
+		}
+	})
+}
+
+func TestReadFromFunc(t *testing.T) {
+	atoi := func(b []byte) (int, bool) {
+		n, err := strconv.Atoi(string(b))
+		return n, err == nil
+	}
+	str := func(b []byte) (string, bool) { return string(b), true }
+
+	t.Run("round trip", func(t *testing.T) {
+		src := New(WithItems([]int{1, 2, 3}))
+		var buf bytes.Buffer
+		_, _ = WriteToFunc(src, &buf, func(n int) []byte { return strconv.AppendInt(nil, int64(n), 10) })
+
+		s, err := ReadFromFunc(&buf, atoi)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !Equal(s, src) {
+			t.Errorf("expected %v, got %v", src.ToSlice(), s.ToSlice())
+		}
+	})
+
+	t.Run("reads WriteTo output", func(t *testing.T) {
+		items := []string{"a", "b", "", "multi\nline", strings.Repeat("x", 100_000)}
+		s, err := ReadFromFunc(records(items...), str)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, items) {
+			t.Errorf("expected %d items written by WriteTo, got %d", len(items), len(got))
+		}
+	})
+
+	t.Run("decoder stops", func(t *testing.T) {
+		s, err := ReadFromFunc(records("1", "2", "end", "3"), atoi)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("expected [1 2], got %v", got)
+		}
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		_, err := ReadFromFunc(records("1", "2", "3"), atoi, WithCapacity[int](2))
+		if !errors.Is(err, ErrOverflow) {
+			t.Errorf("expected ErrOverflow, got %v", err)
+		}
+	})
+
+	t.Run("no reporter on error", func(t *testing.T) {
+		reports := make(chan Stats, 100)
+		report := WithMetricsReporter[int](time.Millisecond, func(st Stats) { reports <- st })
+		if _, err := ReadFromFunc(records("1", "2", "3"), atoi, WithCapacity[int](2), report); !errors.Is(err, ErrOverflow) {
+			t.Fatalf("expected ErrOverflow, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if n := len(reports); n != 0 {
+			t.Errorf("%d report(s) from a stack that was never returned, want 0", n)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		data := records("1", "22").Bytes()
+		if _, err := ReadFromFunc(bytes.NewReader(data[:len(data)-1]), atoi); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+		}
+	})
+
+	t.Run("reader error", func(t *testing.T) {
+		r := io.MultiReader(records("1"), iotest.ErrReader(errIO))
+		if _, err := ReadFromFunc(r, atoi); !errors.Is(err, errIO) {
+			t.Errorf("expected reader error, got %v", err)
+		}
+	})
+
+	t.Run("byte slices", func(t *testing.T) {
+		s, err := ReadFromFunc(records("ab", "cd"), func(b []byte) ([]byte, bool) {
+			return bytes.Clone(b), true
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if top, _ := s.Peek(); string(top) != "cd" {
+			t.Errorf("expected top %q, got %q", "cd", top)
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package stack
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrOverflow is returned when attempting to push an item to a stack
@@ -38,4 +41,166 @@ var (
 	//		fmt.Println("Stack is empty")
 	//	}
 	ErrUnderflow = errors.New("stack underflow")
+
+	// ErrInvalidCapacity is returned when a capacity less than UnlimitedCapacity
+	// is supplied to a method that changes the capacity of an existing stack,
+	// or by NewChecked when a capacity option is invalid.
+	//
+	// Example:
+	//
+	//	s := stack.New[int]()
+	//	err := s.ResetWithCapacity(-5) // Returns ErrInvalidCapacity
+	ErrInvalidCapacity = errors.New("invalid stack capacity")
+
+	// ErrInvalidOption is returned by NewChecked when an option other than a
+	// capacity is invalid, such as a negative limit passed to WithMaxBytes.
+	//
+	// Example:
+	//
+	//	_, err := stack.NewChecked[[]byte](stack.WithMaxBytes[[]byte](-1, sizeOf)) // Returns ErrInvalidOption
+	ErrInvalidOption = errors.New("invalid stack option")
+
+	// ErrNoEncoder is returned by WriteTo when the stack has no encoder registered
+	// with WithEncoder, or by MarshalBinary and UnmarshalBinary when it has no
+	// codec registered with WithBinaryCodec, and its items are neither strings
+	// nor byte slices.
+	//
+	// Example:
+	//
+	//	s := stack.New[int](stack.WithItems([]int{1, 2}))
+	//	_, err := s.WriteTo(os.Stdout) // Returns ErrNoEncoder
+	ErrNoEncoder = errors.New("no stack encoder registered")
+
+	// ErrTimeout is returned by PopWithTimeout when no item becomes available
+	// before the timeout elapses.
+	//
+	// Example:
+	//
+	//	s := stack.New[int]()
+	//	_, err := s.PopWithTimeout(time.Second) // Returns ErrTimeout after one second
+	ErrTimeout = errors.New("stack operation timed out")
+
+	// ErrClosed is returned by operations that push or pop items once the stack
+	// has been closed with Close, and by a second call to Close. Goroutines
+	// blocked in BlockingPop or BlockingPush when the stack is closed are woken
+	// and also receive ErrClosed.
+	//
+	// Example:
+	//
+	//	s := stack.New[int]()
+	//	s.Close()
+	//	err := s.Push(1) // Returns ErrClosed
+	ErrClosed = errors.New("stack closed")
+
+	// ErrFrozen is returned by operations that modify the stack once it has
+	// been made read-only with Freeze. Methods that only read the stack keep
+	// working.
+	//
+	// Example:
+	//
+	//	s := stack.New[int](stack.WithItems([]int{1, 2}))
+	//	s.Freeze()
+	//	_, err := s.Pop() // Returns ErrFrozen
+	ErrFrozen = errors.New("stack frozen")
+
+	// ErrDuplicate is returned when pushing an item that is already on a
+	// stack created with WithUniqueness(true).
+	//
+	// Example:
+	//
+	//	s := stack.New[int](stack.WithUniqueness[int](true))
+	//	s.Push(1)
+	//	err := s.Push(1) // Returns ErrDuplicate
+	ErrDuplicate = errors.New("duplicate stack item")
+
+	// ErrIndexOutOfRange is returned by PeekAt and SwapAt when a requested
+	// depth is negative or not less than the size of the stack, and by PeekN
+	// and SplitAt when the count or split index is negative.
+	//
+	// Example:
+	//
+	//	s := stack.New[int](stack.WithItems([]int{1, 2}))
+	//	_, err := s.PeekAt(2) // Returns ErrIndexOutOfRange
+	ErrIndexOutOfRange = errors.New("stack index out of range")
+
+	// ErrNoComparator is returned by Min and Max when the stack was created
+	// without an ordering, either from NewOrdered or from WithComparator.
+	//
+	// Example:
+	//
+	//	s := stack.New[int](stack.WithItems([]int{1, 2}))
+	//	_, err := s.Min() // Returns ErrNoComparator
+	ErrNoComparator = errors.New("no stack comparator registered")
 )
+
+// OverflowError is returned by Push when the stack is full. It records the name,
+// capacity and size of the stack at the moment the push failed, and wraps
+// ErrOverflow, so errors.Is(err, ErrOverflow) continues to work.
+//
+// Example:
+//
+//	var overflow *stack.OverflowError
+//	if errors.As(s.Push(4), &overflow) {
+//		log.Printf("stack full: %d/%d items", overflow.Size, overflow.Capacity)
+//	}
+type OverflowError struct {
+	// Name is the name of the stack set with WithName, if any.
+	Name string
+
+	// Capacity is the item capacity of the stack, or UnlimitedCapacity if it
+	// has none. It is reported even when the push failed because of the byte
+	// limit set by WithMaxBytes rather than the item capacity.
+	Capacity int
+
+	// Size is the number of items on the stack when the push failed.
+	Size int
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("%v%s (size %d, capacity %d)", ErrOverflow, inStack(e.Name), e.Size, e.Capacity)
+}
+
+// Unwrap returns ErrOverflow.
+func (e *OverflowError) Unwrap() error {
+	return ErrOverflow
+}
+
+// UnderflowError is returned by Pop, Peek and PeekN when the stack holds too few
+// items. It records the name, capacity and size of the stack at the moment the call
+// failed, and wraps ErrUnderflow, so errors.Is(err, ErrUnderflow) continues to work.
+//
+// Example:
+//
+//	var underflow *stack.UnderflowError
+//	if _, err := s.PeekN(5); errors.As(err, &underflow) {
+//		log.Printf("only %d items available", underflow.Size)
+//	}
+type UnderflowError struct {
+	// Name is the name of the stack set with WithName, if any.
+	Name string
+
+	// Capacity is the capacity of the stack, or UnlimitedCapacity.
+	Capacity int
+
+	// Size is the number of items on the stack when the call failed.
+	Size int
+}
+
+func (e *UnderflowError) Error() string {
+	return fmt.Sprintf("%v%s (size %d, capacity %d)", ErrUnderflow, inStack(e.Name), e.Size, e.Capacity)
+}
+
+// Unwrap returns ErrUnderflow.
+func (e *UnderflowError) Unwrap() error {
+	return ErrUnderflow
+}
+
+// inStack returns the suffix naming the stack in an error message, or "" if
+// the stack is unnamed.
+func inStack(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" in %q", name)
+}
@@ -0,0 +1,433 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"unsafe"
+)
+
+// Contains reports whether val is present in the stack.
+// The search runs over a snapshot of the stack and stops at the first match.
+//
+// Example:
+//
+//	s := stack.New[string](stack.WithItems([]string{"a", "b"}))
+//	stack.Contains(s, "a") // true
+func Contains[T comparable](s Stack[T], val T) bool {
+	for _, item := range s.ToSlice() {
+		if item == val {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IndexOf returns the depth of the first item from the top that equals val,
+// where 0 is the top item, or -1 if val is not present. The search runs over
+// a snapshot taken under the read lock.
+//
+// Example:
+//
+//	s := stack.New[string](stack.WithItems([]string{"a", "b", "c"}))
+//	stack.IndexOf(s, "a") // 2
+func IndexOf[T comparable](s Stack[T], val T) int {
+	items, _ := snapshot(s)
+	for depth := range items {
+		if items[len(items)-1-depth] == val {
+			return depth
+		}
+	}
+
+	return -1
+}
+
+// Any reports whether pred returns true for at least one item of s. Items are
+// checked from top to bottom over a snapshot taken under the read lock, and
+// the check stops at the first match. An empty stack reports false.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{1, 2, 3}))
+//	stack.Any(s, func(n int) bool { return n > 2 }) // true
+func Any[T any](s Stack[T], pred func(T) bool) bool {
+	items, _ := snapshot(s)
+	for i := len(items) - 1; i >= 0; i-- {
+		if pred(items[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// All reports whether pred returns true for every item of s. Items are checked
+// from top to bottom over a snapshot taken under the read lock, and the check
+// stops at the first item that fails. An empty stack reports true.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{1, 2, 3}))
+//	stack.All(s, func(n int) bool { return n > 0 }) // true
+func All[T any](s Stack[T], pred func(T) bool) bool {
+	items, _ := snapshot(s)
+	for i := len(items) - 1; i >= 0; i-- {
+		if !pred(items[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Count returns the number of items of s for which pred returns true, counted
+// over a snapshot taken under the read lock. The stack is not modified, and
+// pred is called without holding the lock.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{1, 2, 3, 4}))
+//	stack.Count(s, func(n int) bool { return n%2 == 0 }) // 2
+func Count[T any](s Stack[T], pred func(T) bool) int {
+	items, _ := snapshot(s)
+
+	n := 0
+	for _, item := range items {
+		if pred(item) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Number is a constraint that permits any integer or floating-point type.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Sum returns the sum of all items in the stack, computed over a snapshot.
+// An empty stack sums to zero.
+//
+// Integer sums wrap around on overflow, following Go's native arithmetic.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{1, 2, 3}))
+//	stack.Sum(s) // 6
+func Sum[T Number](s Stack[T]) T {
+	var total T
+	for _, item := range s.ToSlice() {
+		total += item
+	}
+
+	return total
+}
+
+// Average returns the arithmetic mean of all items in the stack, computed over a snapshot.
+// Items are converted to float64 before summing, so integer items cannot overflow.
+// Returns ErrUnderflow if the stack is empty.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{1, 2}))
+//	avg, _ := stack.Average(s) // 1.5
+func Average[T Number](s Stack[T]) (float64, error) {
+	items := s.ToSlice()
+	if len(items) == 0 {
+		return 0, ErrUnderflow
+	}
+
+	var total float64
+	for _, item := range items {
+		total += float64(item)
+	}
+
+	return total / float64(len(items)), nil
+}
+
+// Histogram counts the items of s in the ranges separated by buckets, which
+// must be sorted in ascending order. The result has len(buckets)+1 entries:
+// the first counts items below buckets[0], entry i counts items at least
+// buckets[i-1] and below buckets[i], and the last counts items at least the
+// final bucket, so every item is counted exactly once. NaN items are counted
+// in the last entry. The items are counted over a snapshot.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{-5, 1, 10, 15, 99}))
+//	stack.Histogram(s, []int{0, 10, 20}) // [1 1 2 1]
+//
+// Panics if buckets are not sorted.
+func Histogram[T Number](s Stack[T], buckets []T) []int {
+	if !slices.IsSorted(buckets) {
+		panic("cannot build a histogram from unsorted buckets")
+	}
+
+	counts := make([]int, len(buckets)+1)
+	for _, item := range s.ToSlice() {
+		counts[sort.Search(len(buckets), func(i int) bool { return item < buckets[i] })]++
+	}
+
+	return counts
+}
+
+// Map returns a new stack holding the result of applying fn to each item of s,
+// in the same order and with the same capacity. The source stack is not modified,
+// and fn is called on a snapshot without holding the lock.
+//
+// Example:
+//
+//	words := stack.New[string](stack.WithItems([]string{"a", "bb"}))
+//	lengths := stack.Map(words, func(w string) int { return len(w) }) // [1 2]
+func Map[T, U any](s Stack[T], fn func(T) U) Stack[U] {
+	items, capacity := snapshot(s)
+
+	result := newStack(WithCapacity[U](capacity))
+	for _, item := range items {
+		result.push(fn(item))
+	}
+
+	return result
+}
+
+// Zip returns a new stack pairing the items of a and b from the bottom up,
+// holding combine(a[i], b[i]) at each position. The result has as many items
+// as the shorter stack; the topmost items of the longer stack are ignored.
+// Each stack is copied under its own read lock, combine is called without
+// holding either, and the result has unlimited capacity.
+//
+// Example:
+//
+//	names := stack.New[string](stack.WithItems([]string{"a", "b", "c"}))
+//	ages := stack.New[int](stack.WithItems([]int{30, 40}))
+//	stack.Zip(names, ages, func(n string, a int) string {
+//		return fmt.Sprintf("%s=%d", n, a)
+//	}) // [a=30 b=40]
+func Zip[T, U, V any](a Stack[T], b Stack[U], combine func(T, U) V) Stack[V] {
+	left, _ := snapshot(a)
+	right, _ := snapshot(b)
+
+	result := newStack[V]()
+	for i := range min(len(left), len(right)) {
+		result.push(combine(left[i], right[i]))
+	}
+
+	return result
+}
+
+// Partition returns two new stacks holding the items of s for which pred
+// returns true and false respectively, each in their original order and with
+// the same capacity as s. The source stack is not modified, and pred is called
+// on a snapshot without holding the lock.
+//
+// Example:
+//
+//	s := stack.New[int](stack.WithItems([]int{1, 2, 3, 4}))
+//	even, odd := stack.Partition(s, func(n int) bool { return n%2 == 0 }) // [2 4], [1 3]
+func Partition[T any](s Stack[T], pred func(T) bool) (matched, rest Stack[T]) {
+	items, capacity := snapshot(s)
+
+	yes := newStack(WithCapacity[T](capacity))
+	no := newStack(WithCapacity[T](capacity))
+	for _, item := range items {
+		if pred(item) {
+			yes.push(item)
+		} else {
+			no.push(item)
+		}
+	}
+
+	return yes, no
+}
+
+// Reduce folds the items of s, from bottom to top, into a single value, starting
+// from init and combining the running result with each item using fn. The items
+// are copied under the read lock and fn is called without holding it.
+//
+// Example:
+//
+//	s := stack.New[string](stack.WithItems([]string{"a", "b", "c"}))
+//	stack.Reduce(s, "", func(acc, val string) string { return acc + val }) // "abc"
+func Reduce[T, A any](s Stack[T], init A, fn func(acc A, val T) A) A {
+	items, _ := snapshot(s)
+
+	acc := init
+	for _, item := range items {
+		acc = fn(acc, item)
+	}
+
+	return acc
+}
+
+// snapshot returns a copy of the items of s, bottom to top, together with its
+// capacity, read atomically where the implementation allows it.
+func snapshot[T any](s Stack[T]) ([]T, int) {
+	switch s := s.(type) {
+	case *stack[T]:
+		s.rlock()
+		defer s.runlock()
+
+		items := make([]T, len(s.items))
+		copy(items, s.items)
+
+		return items, s.capacity
+	case *sharded[T]:
+		s.rlockAll()
+		defer s.runlockAll()
+
+		return s.gather(), s.capacity
+	default:
+		return s.ToSlice(), s.Capacity()
+	}
+}
+
+// Equal reports whether a and b hold the same items in the same order.
+// Capacity is not compared, so two empty stacks are always equal.
+//
+// Both stacks are locked together, in a consistent order, so the comparison
+// sees a coherent view of each.
+func Equal[T comparable](a, b Stack[T]) bool {
+	if locks, ok := lockOrder(a, b); ok {
+		defer rlockStacks(locks)()
+
+		return slices.Equal(lockedItems(a), lockedItems(b))
+	}
+
+	return slices.Equal(a.ToSlice(), b.ToSlice())
+}
+
+// EqualTop reports whether the top items of a and b are equal, or returns the
+// *UnderflowError of the first stack found to be empty.
+//
+// Both stacks are read-locked together, in a consistent order, so unlike two
+// separate calls to Peek, the tops are read at the same moment.
+func EqualTop[T comparable](a, b Stack[T]) (bool, error) {
+	peek := Stack[T].Peek
+	if locks, ok := lockOrder(a, b); ok {
+		defer rlockStacks(locks)()
+		peek = lockedPeek[T]
+	}
+
+	x, err := peek(a)
+	if err != nil {
+		return false, err
+	}
+	y, err := peek(b)
+	if err != nil {
+		return false, err
+	}
+
+	return x == y, nil
+}
+
+// Merge returns a new stack holding the items of bottom followed by the items
+// of top, so that the top item of top becomes the top of the result. Neither
+// input is modified.
+//
+// Both stacks are read-locked together, in a consistent order, so the result
+// reflects a coherent view of each. The result has unlimited capacity unless
+// opts say otherwise; opts are applied as for New.
+//
+// Example:
+//
+//	a := stack.New[int](stack.WithItems([]int{1, 2}))
+//	b := stack.New[int](stack.WithItems([]int{3, 4}))
+//	merged := stack.Merge(a, b) // [1 2 3 4], top is 4
+//
+// Panics if the combined items exceed a capacity set in opts.
+func Merge[T any](bottom, top Stack[T], opts ...Option[T]) Stack[T] {
+	var items []T
+	if locks, ok := lockOrder(bottom, top); ok {
+		unlock := rlockStacks(locks)
+		items = slices.Concat(lockedItems(bottom), lockedItems(top))
+		unlock()
+	} else {
+		items = slices.Concat(bottom.ToSlice(), top.ToSlice())
+	}
+
+	s := newStack(append(slices.Clip(opts), WithItems(items))...)
+	s.startReporting()
+
+	return s
+}
+
+// errTopMismatch aborts the transaction in CompareAndSwapTop.
+var errTopMismatch = errors.New("top item does not match")
+
+// CompareAndSwapTop atomically replaces the top item of s with new if it equals
+// old, and reports whether the swap was made. Returns ErrUnderflow if the stack
+// is empty. The replacement counts as a pop and a push for observers and Stats.
+//
+// Example:
+//
+//	s := stack.New[string](stack.WithItems([]string{"draft"}))
+//	swapped, _ := stack.CompareAndSwapTop(s, "draft", "final") // true
+//	swapped, _ = stack.CompareAndSwapTop(s, "draft", "other")  // false
+func CompareAndSwapTop[T comparable](s Stack[T], old, new T) (bool, error) {
+	err := s.Transaction(func(tx TxStack[T]) error {
+		top, err := tx.Peek()
+		if err != nil {
+			return err
+		}
+		if top != old {
+			return errTopMismatch
+		}
+
+		_, _ = tx.Pop()
+		return tx.Push(new)
+	})
+
+	switch {
+	case errors.Is(err, errTopMismatch):
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+// MemoryUsage estimates the number of bytes used by the contents of s. It adds
+// the size of the storage allocated for the items, including unused spare
+// capacity and the tracking kept by NewOrdered, to the sum of sizeOf over every
+// item. sizeOf should report the memory an item refers to beyond its own fixed
+// size, such as the length of a byte slice or string.
+//
+// The stack is read-locked while the items are measured, so the estimate
+// reflects a consistent state and may be taken concurrently with other reads.
+// sizeOf must not modify the stack.
+//
+// Example:
+//
+//	s := stack.New[[]byte](stack.WithItems([][]byte{make([]byte, 1024)}))
+//	stack.MemoryUsage(s, func(b []byte) int { return cap(b) }) // 1024 + 24
+func MemoryUsage[T any](s Stack[T], sizeOf func(T) int) int {
+	var zero T
+	elem := int(unsafe.Sizeof(zero))
+
+	locks, ok := lockOrder(s)
+	if !ok {
+		items := s.ToSlice()
+		total := len(items) * elem
+		for _, item := range items {
+			total += sizeOf(item)
+		}
+		return total
+	}
+
+	defer rlockStacks(locks)()
+
+	total := 0
+	for _, sh := range locks {
+		total += (cap(sh.items) + cap(sh.mins) + cap(sh.maxs)) * elem
+		for _, item := range sh.items {
+			total += sizeOf(item)
+		}
+	}
+
+	return total
+}
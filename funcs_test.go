@@ -0,0 +1,420 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestContains(t *testing.T) {
+	s := New[string](WithItems([]string{"a", "b", "c"}))
+
+	for _, val := range []string{"a", "b", "c"} {
+		if !Contains(s, val) {
+			t.Errorf("Contains(%q) = false, want true", val)
+		}
+	}
+	if Contains(s, "d") {
+		t.Error(`Contains("d") = true, want false`)
+	}
+	if Contains(New[string](), "a") {
+		t.Error(`Contains("a") on empty stack = true, want false`)
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	s := New[string](WithItems([]string{"a", "b", "a", "c"}))
+
+	tests := map[string]int{"c": 0, "a": 1, "b": 2, "d": -1}
+	for val, want := range tests {
+		if got := IndexOf(s, val); got != want {
+			t.Errorf("IndexOf(%q) = %d, want %d", val, got, want)
+		}
+	}
+	if got := IndexOf(New[string](), "a"); got != -1 {
+		t.Errorf(`IndexOf("a") on empty stack = %d, want -1`, got)
+	}
+}
+
+func TestAnyAll(t *testing.T) {
+	s := New[int](WithItems([]int{2, 4, 5}))
+	even := func(n int) bool { return n%2 == 0 }
+	positive := func(n int) bool { return n > 0 }
+
+	if !Any(s, even) {
+		t.Error("Any(even) = false, want true")
+	}
+	if Any(s, func(n int) bool { return n > 5 }) {
+		t.Error("Any(> 5) = true, want false")
+	}
+	if All(s, even) {
+		t.Error("All(even) = true, want false")
+	}
+	if !All(s, positive) {
+		t.Error("All(positive) = false, want true")
+	}
+
+	var checked []int
+	Any(s, func(n int) bool {
+		checked = append(checked, n)
+		return n == 4
+	})
+	if !slices.Equal(checked, []int{5, 4}) {
+		t.Errorf("Any() checked %v, want [5 4]", checked)
+	}
+
+	empty := New[int]()
+	if Any(empty, positive) || !All(empty, positive) {
+		t.Error("Any() and All() of empty stack = true, false, want false, true")
+	}
+}
+
+func TestCount(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3, 4}))
+	even := func(n int) bool { return n%2 == 0 }
+
+	if got := Count(s, even); got != 2 {
+		t.Errorf("Count(even) = %d, want 2", got)
+	}
+	if got := Count(s, func(int) bool { return false }); got != 0 {
+		t.Errorf("Count(never) = %d, want 0", got)
+	}
+	if size := s.Size(); size != 4 {
+		t.Errorf("Size() after Count() = %d, want 4", size)
+	}
+
+	sharded := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5, 6}))
+	if got := Count(sharded, even); got != 3 {
+		t.Errorf("Count(even) of sharded stack = %d, want 3", got)
+	}
+}
+
+func TestSum(t *testing.T) {
+	if got := Sum(New[int]()); got != 0 {
+		t.Errorf("Sum() of empty stack = %d, want 0", got)
+	}
+	if got := Sum(New[int](WithItems([]int{1, 2, 3}))); got != 6 {
+		t.Errorf("Sum() = %d, want 6", got)
+	}
+	if got := Sum(New[float64](WithItems([]float64{0.5, 0.25}))); got != 0.75 {
+		t.Errorf("Sum() = %v, want 0.75", got)
+	}
+	if got := Sum(New[uint8](WithItems([]uint8{200, 100}))); got != 44 {
+		t.Errorf("Sum() with uint8 overflow = %d, want 44 (wrapped)", got)
+	}
+}
+
+func TestAverage(t *testing.T) {
+	if _, err := Average(New[int]()); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Average() of empty stack error = %v, want ErrUnderflow", err)
+	}
+
+	got, err := Average(New[int](WithItems([]int{1, 2})))
+	if err != nil || got != 1.5 {
+		t.Errorf("Average() = %v, %v, want 1.5, nil", got, err)
+	}
+
+	got, err = Average(New[uint8](WithItems([]uint8{200, 100})))
+	if err != nil || got != 150 {
+		t.Errorf("Average() = %v, %v, want 150, nil", got, err)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	s := New[int](WithItems([]int{-5, 0, 1, 10, 15, 20, 99}))
+
+	if got := Histogram(s, []int{0, 10, 20}); !slices.Equal(got, []int{1, 2, 2, 2}) {
+		t.Errorf("Histogram() = %v, want [1 2 2 2]", got)
+	}
+	if got := Histogram(s, nil); !slices.Equal(got, []int{7}) {
+		t.Errorf("Histogram() without buckets = %v, want [7]", got)
+	}
+	if got := Histogram(New[float64](), []float64{0.5}); !slices.Equal(got, []int{0, 0}) {
+		t.Errorf("Histogram() of empty stack = %v, want [0 0]", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Histogram() with unsorted buckets did not panic")
+		}
+	}()
+	Histogram(s, []int{10, 0})
+}
+
+func TestMap(t *testing.T) {
+	words := New[string](WithCapacity[string](5), WithItems([]string{"a", "bb", "ccc"}))
+
+	lengths := Map(words, func(w string) int { return len(w) })
+
+	if got := lengths.ToSlice(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Map() = %v, want [1 2 3]", got)
+	}
+	if got := lengths.Capacity(); got != 5 {
+		t.Errorf("Map().Capacity() = %d, want 5", got)
+	}
+	if got := words.ToSlice(); !slices.Equal(got, []string{"a", "bb", "ccc"}) {
+		t.Errorf("source after Map() = %v, want [a bb ccc]", got)
+	}
+
+	sharded := NewSharded[int](2, WithItems([]int{1, 2, 3}))
+	doubled := Map(sharded, func(v int) int { return v * 2 })
+	if got := doubled.ToSlice(); !slices.Equal(got, []int{2, 4, 6}) {
+		t.Errorf("Map() of sharded stack = %v, want [2 4 6]", got)
+	}
+}
+
+func TestZip(t *testing.T) {
+	names := New[string](WithItems([]string{"a", "b", "c"}))
+	ages := NewSharded[int](2, WithItems([]int{30, 40}))
+
+	zipped := Zip(names, ages, func(n string, a int) string { return n + strconv.Itoa(a) })
+	if got := zipped.ToSlice(); !slices.Equal(got, []string{"a30", "b40"}) {
+		t.Errorf("Zip() = %v, want [a30 b40]", got)
+	}
+	if got := zipped.Capacity(); got != UnlimitedCapacity {
+		t.Errorf("Zip().Capacity() = %d, want %d", got, UnlimitedCapacity)
+	}
+
+	if got := Zip(New[int](), names, func(int, string) int { return 0 }); !got.IsEmpty() {
+		t.Errorf("Zip() with empty stack = %v, want empty", got.ToSlice())
+	}
+}
+
+func TestReduce(t *testing.T) {
+	s := New[string](WithItems([]string{"a", "b", "c"}))
+
+	concat := Reduce(s, "", func(acc, val string) string { return acc + val })
+	if concat != "abc" {
+		t.Errorf("Reduce() = %q, want %q", concat, "abc")
+	}
+
+	count := Reduce(s, 0, func(acc int, _ string) int { return acc + 1 })
+	if count != 3 {
+		t.Errorf("Reduce() count = %d, want 3", count)
+	}
+
+	if got := Reduce(New[int](), 42, func(acc, val int) int { return acc + val }); got != 42 {
+		t.Errorf("Reduce() of empty stack = %d, want 42", got)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	s := New[int](WithCapacity[int](10), WithItems([]int{1, 2, 3, 4, 5}))
+
+	even, odd := Partition(s, func(n int) bool { return n%2 == 0 })
+	if got := even.ToSlice(); !slices.Equal(got, []int{2, 4}) {
+		t.Errorf("matched = %v, want [2 4]", got)
+	}
+	if got := odd.ToSlice(); !slices.Equal(got, []int{1, 3, 5}) {
+		t.Errorf("rest = %v, want [1 3 5]", got)
+	}
+	if even.Capacity() != 10 || odd.Capacity() != 10 {
+		t.Errorf("capacities = %d, %d, want 10, 10", even.Capacity(), odd.Capacity())
+	}
+	if size := s.Size(); size != 5 {
+		t.Errorf("source Size() after Partition() = %d, want 5", size)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Stack[int]
+		want bool
+	}{
+		{name: "both empty", a: New[int](), b: New[int](WithCapacity[int](3)), want: true},
+		{name: "same items", a: New[int](WithItems([]int{1, 2})), b: New[int](WithItems([]int{1, 2})), want: true},
+		{name: "different order", a: New[int](WithItems([]int{1, 2})), b: New[int](WithItems([]int{2, 1})), want: false},
+		{name: "different size", a: New[int](WithItems([]int{1, 2})), b: New[int](WithItems([]int{1})), want: false},
+		{name: "sharded", a: NewSharded[int](2, WithItems([]int{1, 2, 3})), b: New[int](WithItems([]int{1, 2, 3})), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := Equal(tt.b, tt.a); got != tt.want {
+				t.Errorf("Equal() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("same stack", func(t *testing.T) {
+		s := New[int](WithItems([]int{1}))
+		if !Equal(s, s) {
+			t.Error("Equal(s, s) = false, want true")
+		}
+	})
+
+	t.Run("concurrent opposite order", func(t *testing.T) {
+		a := New[int](WithItems([]int{1}))
+		b := New[int](WithItems([]int{1}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				Equal(a, b)
+				_ = a.Push(1)
+			}()
+			go func() {
+				defer wg.Done()
+				Equal(b, a)
+				_ = b.Push(1)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestEqualTop(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Stack[int]
+		want bool
+		err  error
+	}{
+		{name: "same top", a: New[int](WithItems([]int{1, 2})), b: New[int](WithItems([]int{3, 2})), want: true},
+		{name: "different top", a: New[int](WithItems([]int{1, 2})), b: New[int](WithItems([]int{2, 1})), want: false},
+		{name: "sharded", a: NewSharded[int](2, WithItems([]int{5})), b: New[int](WithItems([]int{5})), want: true},
+		{name: "foreign", a: foreignStack{New[int](WithItems([]int{7}))}, b: New[int](WithItems([]int{7})), want: true},
+		{name: "one empty", a: New[int](WithItems([]int{1})), b: New[int](), err: ErrUnderflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, pair := range [][2]Stack[int]{{tt.a, tt.b}, {tt.b, tt.a}} {
+				got, err := EqualTop(pair[0], pair[1])
+				if got != tt.want || !errors.Is(err, tt.err) {
+					t.Errorf("EqualTop() = %v, %v, want %v, %v", got, err, tt.want, tt.err)
+				}
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		bottom := New[int](WithItems([]int{1, 2}), WithCapacity[int](2))
+		top := NewSharded[int](2, WithItems([]int{3, 4}))
+
+		merged := Merge(bottom, top)
+		if got := merged.ToSlice(); !slices.Equal(got, []int{1, 2, 3, 4}) {
+			t.Errorf("expected [1 2 3 4], got %v", got)
+		}
+		if merged.Capacity() != UnlimitedCapacity {
+			t.Errorf("expected unlimited capacity, got %d", merged.Capacity())
+		}
+		if bottom.Size() != 2 || top.Size() != 2 {
+			t.Error("expected inputs to be unchanged")
+		}
+
+		_ = merged.Push(5)
+		if bottom.Size() != 2 {
+			t.Error("expected merged stack to be independent of its inputs")
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		merged := Merge(New[int](WithItems([]int{1})), New[int](), WithCapacity[int](3))
+		if merged.Capacity() != 3 {
+			t.Errorf("expected capacity 3, got %d", merged.Capacity())
+		}
+	})
+
+	t.Run("same stack", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2}))
+		if got := Merge(s, s).ToSlice(); !slices.Equal(got, []int{1, 2, 1, 2}) {
+			t.Errorf("expected [1 2 1 2], got %v", got)
+		}
+	})
+
+	t.Run("over capacity panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic when items exceed capacity")
+			}
+		}()
+		Merge(New[int](WithItems([]int{1, 2})), New[int](WithItems([]int{3})), WithCapacity[int](2))
+	})
+}
+
+func TestCompareAndSwapTop(t *testing.T) {
+	for name, s := range map[string]Stack[int]{
+		"plain":   New[int](WithItems([]int{1, 2})),
+		"sharded": NewSharded[int](1, WithItems([]int{1, 2})),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if swapped, err := CompareAndSwapTop(s, 2, 20); !swapped || err != nil {
+				t.Errorf("CompareAndSwapTop(2, 20) = %v, %v, want true, nil", swapped, err)
+			}
+			if swapped, err := CompareAndSwapTop(s, 2, 30); swapped || err != nil {
+				t.Errorf("CompareAndSwapTop(2, 30) = %v, %v, want false, nil", swapped, err)
+			}
+			if got := s.ToSlice(); !slices.Equal(got, []int{1, 20}) {
+				t.Errorf("ToSlice() = %v, want [1 20]", got)
+			}
+
+			s.Clear()
+			if _, err := CompareAndSwapTop(s, 0, 1); !errors.Is(err, ErrUnderflow) {
+				t.Errorf("CompareAndSwapTop() on empty stack error = %v, want ErrUnderflow", err)
+			}
+		})
+	}
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		s := New[int](WithItems([]int{0}))
+
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					for {
+						top, _ := s.Peek()
+						if ok, _ := CompareAndSwapTop(s, top, top+1); ok {
+							break
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		if top, _ := s.Peek(); top != 800 {
+			t.Errorf("Peek() after concurrent increments = %d, want 800", top)
+		}
+	})
+}
+
+func TestMemoryUsage(t *testing.T) {
+	size := func(b []byte) int { return len(b) }
+	header := int(unsafe.Sizeof([]byte(nil)))
+
+	s := newStack[[]byte](WithCapacity[[]byte](4))
+	s.Grow(4)
+	_ = s.PushMany(make([]byte, 100), make([]byte, 50))
+
+	if got, want := MemoryUsage[[]byte](s, size), 150+4*header; got != want {
+		t.Errorf("MemoryUsage() = %d, want %d", got, want)
+	}
+	if s.Size() != 2 {
+		t.Errorf("Size() after MemoryUsage() = %d, want 2", s.Size())
+	}
+
+	ordered := NewOrdered[int](WithItems([]int{1, 2, 3}))
+	if got := MemoryUsage(ordered, func(int) int { return 0 }); got < 9*int(unsafe.Sizeof(0)) {
+		t.Errorf("MemoryUsage() of ordered stack = %d, want tracking included", got)
+	}
+
+	sharded := NewSharded[[]byte](2, WithItems([][]byte{make([]byte, 10), make([]byte, 20)}))
+	if got := MemoryUsage(sharded, size); got < 30+2*header {
+		t.Errorf("MemoryUsage() of sharded stack = %d, want at least %d", got, 30+2*header)
+	}
+}
@@ -0,0 +1,107 @@
+package stack
+
+// Observer receives notifications of successful pushes and pops, for example
+// to record metrics. See WithObserver.
+type Observer[T any] interface {
+	// OnPush is called after val has been pushed onto the stack.
+	OnPush(val T)
+
+	// OnPop is called after val has been popped from the stack.
+	OnPop(val T)
+}
+
+// hookEvents holds the callbacks owed for changes made while a stack was locked,
+// so they can run after the lock is released.
+type hookEvents[T any] struct {
+	onEvict  func(T)
+	evicted  []T
+	observer Observer[T]
+	pushed   []T
+	popped   []T
+	onEmpty  func()
+}
+
+// pending reports whether there are callbacks to run.
+func (e hookEvents[T]) pending() bool {
+	return len(e.evicted) > 0 || len(e.pushed) > 0 || len(e.popped) > 0 || e.onEmpty != nil
+}
+
+// run invokes the callbacks. It must be called without holding any stack lock.
+func (e hookEvents[T]) run() {
+	for _, item := range e.evicted {
+		e.onEvict(item)
+	}
+	for _, item := range e.pushed {
+		e.observer.OnPush(item)
+	}
+	for _, item := range e.popped {
+		e.observer.OnPop(item)
+	}
+	if e.onEmpty != nil {
+		e.onEmpty()
+	}
+}
+
+// didPush records a successful push of val for the statistics, the observer and
+// the signal channel. Callers must hold the write lock.
+func (s *stack[T]) didPush(val T) {
+	s.stats.pushes.Add(1)
+	s.trackDepth()
+
+	if s.signal != nil {
+		select {
+		case s.signal <- struct{}{}:
+		default:
+		}
+	}
+
+	if s.observer != nil {
+		s.pushed = append(s.pushed, val)
+	}
+}
+
+// didPop records a successful pop of val for the statistics and the observer.
+// Callers must hold the write lock.
+func (s *stack[T]) didPop(val T) {
+	s.stats.pops.Add(1)
+
+	if s.observer != nil {
+		s.popped = append(s.popped, val)
+	}
+}
+
+// takeEvents removes and returns the callbacks owed for changes made so far.
+// Callers must hold the write lock.
+func (s *stack[T]) takeEvents() hookEvents[T] {
+	e := hookEvents[T]{
+		onEvict:  s.onEvict,
+		evicted:  s.evicted,
+		observer: s.observer,
+		pushed:   s.pushed,
+		popped:   s.popped,
+	}
+	s.evicted, s.pushed, s.popped = nil, nil, nil
+	if s.onEmpty != nil && s.lockedSize > 0 && len(s.items) == 0 {
+		e.onEmpty = s.onEmpty
+		s.lockedSize = 0
+	}
+
+	return e
+}
+
+// release releases the write lock and then runs any callbacks for changes made
+// while it was held, so that callbacks are free to use the stack themselves.
+func (s *stack[T]) release() {
+	if s.onEvict == nil && s.observer == nil && s.onEmpty == nil {
+		// No callbacks are configured, so none can be owed.
+		s.unlock()
+		return
+	}
+
+	e := s.takeEvents()
+	s.unlock()
+
+	if e.pending() {
+		e.run()
+	}
+}
@@ -0,0 +1,296 @@
+package stack
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestEvictionHandler(t *testing.T) {
+	newEvicting := func(evicted *[]int, items ...int) Stack[int] {
+		return New[int](
+			WithCapacity[int](3),
+			WithOverflowPolicy[int](OverflowDropOldest),
+			WithEvictionHandler(func(v int) { *evicted = append(*evicted, v) }),
+			WithItems(items),
+		)
+	}
+
+	t.Run("push", func(t *testing.T) {
+		var evicted []int
+		s := newEvicting(&evicted, 1, 2, 3)
+
+		_ = s.Push(4)
+		_ = s.TryPush(5)
+		_ = s.Dup()
+
+		if !slices.Equal(evicted, []int{1, 2, 3}) {
+			t.Errorf("evicted = %v, want [1 2 3]", evicted)
+		}
+	})
+
+	t.Run("push many", func(t *testing.T) {
+		var evicted []int
+		s := newEvicting(&evicted, 1, 2)
+
+		_ = s.PushMany(3, 4, 5, 6, 7)
+
+		if !slices.Equal(evicted, []int{1, 2, 3, 4}) {
+			t.Errorf("evicted = %v, want [1 2 3 4]", evicted)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{5, 6, 7}) {
+			t.Errorf("ToSlice() = %v, want [5 6 7]", got)
+		}
+	})
+
+	t.Run("not called without eviction", func(t *testing.T) {
+		var evicted []int
+		s := newEvicting(&evicted)
+
+		_ = s.PushMany(1, 2, 3)
+		_, _ = s.Pop()
+		_, _ = s.SetCapacity(1)
+		s.Clear()
+
+		if len(evicted) != 0 {
+			t.Errorf("evicted = %v, want none", evicted)
+		}
+	})
+
+	t.Run("handler may use the stack", func(t *testing.T) {
+		var sizes []int
+		var s Stack[int]
+		s = New[int](
+			WithCapacity[int](1),
+			WithOverflowPolicy[int](OverflowDropOldest),
+			WithEvictionHandler(func(int) { sizes = append(sizes, s.Size()) }),
+			WithItems([]int{1}),
+		)
+
+		_ = s.Push(2)
+
+		if !slices.Equal(sizes, []int{1}) {
+			t.Errorf("Size() seen by handler = %v, want [1]", sizes)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		var evicted []int
+		var s Stack[int]
+		s = NewSharded[int](2,
+			WithCapacity[int](2),
+			WithOverflowPolicy[int](OverflowDropOldest),
+			WithEvictionHandler(func(v int) {
+				evicted = append(evicted, v)
+				_ = s.Size()
+			}),
+			WithItems([]int{1, 2}),
+		)
+
+		_ = s.PushMany(3, 4)
+
+		slices.Sort(evicted)
+		if !slices.Equal(evicted, []int{1, 2}) {
+			t.Errorf("evicted = %v, want [1 2]", evicted)
+		}
+	})
+}
+
+type recordingObserver struct {
+	mu     sync.Mutex
+	pushed []int
+	popped []int
+}
+
+func (o *recordingObserver) OnPush(val int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pushed = append(o.pushed, val)
+}
+
+func (o *recordingObserver) OnPop(val int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.popped = append(o.popped, val)
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("successful operations", func(t *testing.T) {
+		obs := &recordingObserver{}
+		s := New[int](WithCapacity[int](4), WithObserver[int](obs))
+
+		_ = s.Push(1)
+		_ = s.PushMany(2, 3)
+		_ = s.TryPush(4)
+		_, _ = s.Pop()
+		_, _ = s.TryPop()
+		_ = s.Dup()
+		_, _ = s.BlockingPop(context.Background())
+		_ = s.BlockingPush(context.Background(), 5)
+
+		if !slices.Equal(obs.pushed, []int{1, 2, 3, 4, 2, 5}) {
+			t.Errorf("pushed = %v, want [1 2 3 4 2 5]", obs.pushed)
+		}
+		if !slices.Equal(obs.popped, []int{4, 3, 2}) {
+			t.Errorf("popped = %v, want [4 3 2]", obs.popped)
+		}
+	})
+
+	t.Run("failed operations", func(t *testing.T) {
+		obs := &recordingObserver{}
+		s := New[int](WithCapacity[int](1), WithObserver[int](obs), WithItems([]int{1}))
+
+		_ = s.Push(2)
+		_ = s.PushMany(2, 3)
+		_ = s.TryPush(2)
+		_ = s.Dup()
+		_, _ = s.Pop()
+		_, _ = s.Pop()
+		_, _ = s.TryPop()
+
+		if len(obs.pushed) != 0 {
+			t.Errorf("pushed = %v, want none", obs.pushed)
+		}
+		if !slices.Equal(obs.popped, []int{1}) {
+			t.Errorf("popped = %v, want [1]", obs.popped)
+		}
+	})
+
+	t.Run("outside the lock", func(t *testing.T) {
+		var s Stack[int]
+		var sizes []int
+		s = New[int](WithObserver[int](observerFunc(func(int) {
+			sizes = append(sizes, s.Size())
+		})))
+
+		_ = s.PushMany(1, 2)
+		_, _ = s.Pop()
+
+		if !slices.Equal(sizes, []int{2, 2, 1}) {
+			t.Errorf("Size() seen by observer = %v, want [2 2 1]", sizes)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		obs := &recordingObserver{}
+		s := NewSharded[int](4, WithObserver[int](obs))
+
+		_ = s.PushMany(1, 2, 3)
+		_ = s.Push(4)
+		for range s.Drain() {
+		}
+
+		if len(obs.pushed) != 4 || len(obs.popped) != 4 {
+			t.Errorf("observed %d pushes and %d pops, want 4 and 4", len(obs.pushed), len(obs.popped))
+		}
+	})
+}
+
+// observerFunc adapts a function to an Observer called for both pushes and pops.
+type observerFunc func(int)
+
+func (f observerFunc) OnPush(val int) { f(val) }
+func (f observerFunc) OnPop(val int)  { f(val) }
+
+func TestSignalChannel(t *testing.T) {
+	t.Run("signals pushes", func(t *testing.T) {
+		ch := make(chan struct{}, 1)
+		s := New[int](WithSignalChannel[int](ch))
+
+		_ = s.Push(1)
+		select {
+		case <-ch:
+		default:
+			t.Fatal("no signal after Push()")
+		}
+
+		_, _ = s.Pop()
+		select {
+		case <-ch:
+			t.Error("signal after Pop(), want none")
+		default:
+		}
+	})
+
+	t.Run("coalesces signals", func(t *testing.T) {
+		ch := make(chan struct{}, 1)
+		s := New[int](WithSignalChannel[int](ch))
+
+		_ = s.PushMany(1, 2, 3)
+		_ = s.Push(4)
+		if n := len(ch); n != 1 {
+			t.Errorf("pending signals = %d, want 1", n)
+		}
+	})
+
+	t.Run("failed push", func(t *testing.T) {
+		ch := make(chan struct{}, 1)
+		s := New[int](WithCapacity[int](0), WithSignalChannel[int](ch))
+
+		_ = s.Push(1)
+		if n := len(ch); n != 0 {
+			t.Errorf("pending signals after failed Push() = %d, want 0", n)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		ch := make(chan struct{}, 1)
+		s := NewSharded[int](4, WithSignalChannel[int](ch))
+
+		_ = s.Push(1)
+		if n := len(ch); n != 1 {
+			t.Errorf("pending signals after Push() = %d, want 1", n)
+		}
+	})
+}
+
+func TestOnEmpty(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		var s Stack[int]
+		calls := 0
+		s = New(WithItems([]int{1, 2}), WithOnEmpty[int](func() {
+			calls++
+			// fn runs outside the lock, so it may use the stack.
+			if !s.IsEmpty() {
+				t.Error("stack not empty when OnEmpty called")
+			}
+		}))
+
+		_, _ = s.Pop()
+		if calls != 0 {
+			t.Fatalf("OnEmpty calls after first Pop() = %d, want 0", calls)
+		}
+		_, _ = s.Pop()
+		if calls != 1 {
+			t.Fatalf("OnEmpty calls after last Pop() = %d, want 1", calls)
+		}
+
+		_, _ = s.Pop()
+		s.Clear()
+		if calls != 1 {
+			t.Errorf("OnEmpty calls on an empty stack = %d, want 1", calls)
+		}
+
+		_ = s.PushMany(3, 4)
+		s.Clear()
+		if calls != 2 {
+			t.Errorf("OnEmpty calls after Clear() = %d, want 2", calls)
+		}
+
+		_ = s.Push(5)
+		s.DrainAll()
+		if calls != 3 {
+			t.Errorf("OnEmpty calls after DrainAll() = %d, want 3", calls)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("NewSharded did not panic")
+			}
+		}()
+		NewSharded(2, WithOnEmpty[int](func() {}))
+	})
+}
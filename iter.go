@@ -0,0 +1,78 @@
+package stack
+
+import (
+	"iter"
+	"slices"
+)
+
+func (s *stack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		items := s.ToSlice()
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(items[i]) {
+				return
+			}
+		}
+	}
+}
+
+func (s *stack[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			val, ok := s.tryPop()
+			if !ok || !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+func (s *stack[T]) ForEach(fn func(T)) {
+	s.rlock()
+	defer s.runlock()
+
+	for _, item := range s.items {
+		fn(item)
+	}
+}
+
+func (s *stack[T]) ForEachReverse(fn func(T)) {
+	s.rlock()
+	defer s.runlock()
+
+	for i := len(s.items) - 1; i >= 0; i-- {
+		fn(s.items[i])
+	}
+}
+
+func (s *stack[T]) PushSeq(seq iter.Seq[T]) error {
+	return s.PushMany(slices.Collect(seq)...)
+}
+
+func (s *sharded[T]) PushSeq(seq iter.Seq[T]) error {
+	return s.PushMany(slices.Collect(seq)...)
+}
+
+func (s *stack[T]) PopWhile(fn func(T) bool) int {
+	return popWhile(s.tryPop, fn)
+}
+
+func (s *sharded[T]) PopWhile(fn func(T) bool) int {
+	return popWhile(s.tryPop, fn)
+}
+
+// popWhile pops items with tryPop and passes them to fn until fn returns false
+// or tryPop fails, and returns the number of items popped.
+func popWhile[T any](tryPop func() (T, bool), fn func(T) bool) int {
+	n := 0
+	for {
+		val, ok := tryPop()
+		if !ok {
+			return n
+		}
+		n++
+		if !fn(val) {
+			return n
+		}
+	}
+}
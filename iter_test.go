@@ -0,0 +1,163 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	t.Run("top to bottom", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2, 3}))
+
+		got := slices.Collect(s.All())
+		want := []int{3, 2, 1}
+		if !slices.Equal(got, want) {
+			t.Errorf("All() = %v, want %v", got, want)
+		}
+		if size := s.Size(); size != 3 {
+			t.Errorf("Size after All() = %d, want 3", size)
+		}
+	})
+
+	t.Run("empty stack", func(t *testing.T) {
+		s := New[int]()
+		for v := range s.All() {
+			t.Errorf("All() on empty stack yielded %d", v)
+		}
+	})
+
+	t.Run("early break", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2, 3}))
+
+		var got []int
+		for v := range s.All() {
+			got = append(got, v)
+			if len(got) == 2 {
+				break
+			}
+		}
+		if !slices.Equal(got, []int{3, 2}) {
+			t.Errorf("All() with break = %v, want [3 2]", got)
+		}
+	})
+
+	t.Run("mutation during iteration", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2, 3}))
+
+		var got []int
+		for v := range s.All() {
+			_ = s.Push(v * 10)
+			got = append(got, v)
+		}
+		if !slices.Equal(got, []int{3, 2, 1}) {
+			t.Errorf("All() while pushing = %v, want [3 2 1]", got)
+		}
+		if size := s.Size(); size != 6 {
+			t.Errorf("Size after pushing during All() = %d, want 6", size)
+		}
+	})
+}
+
+func TestDrain(t *testing.T) {
+	t.Run("empties stack", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2, 3}))
+
+		got := slices.Collect(s.Drain())
+		if !slices.Equal(got, []int{3, 2, 1}) {
+			t.Errorf("Drain() = %v, want [3 2 1]", got)
+		}
+		if !s.IsEmpty() {
+			t.Errorf("Size after Drain() = %d, want 0", s.Size())
+		}
+	})
+
+	t.Run("early break", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2, 3}))
+
+		for v := range s.Drain() {
+			if v == 3 {
+				break
+			}
+		}
+
+		if !slices.Equal(s.ToSlice(), []int{1, 2}) {
+			t.Errorf("ToSlice() after early break = %v, want [1 2]", s.ToSlice())
+		}
+	})
+}
+
+func TestForEach(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3}))
+
+	var got []int
+	s.ForEach(func(v int) {
+		got = append(got, v)
+	})
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("ForEach() visited %v, want [1 2 3]", got)
+	}
+
+	got = nil
+	s.ForEachReverse(func(v int) {
+		got = append(got, v)
+	})
+	if !slices.Equal(got, []int{3, 2, 1}) {
+		t.Errorf("ForEachReverse() visited %v, want [3 2 1]", got)
+	}
+}
+
+func TestPushSeq(t *testing.T) {
+	s := New[int](WithCapacity[int](5), WithItems([]int{0}))
+
+	if err := s.PushSeq(slices.Values([]int{1, 2, 3})); err != nil {
+		t.Fatalf("PushSeq() error = %v", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("ToSlice() after PushSeq() = %v, want [0 1 2 3]", got)
+	}
+
+	if err := s.PushSeq(slices.Values([]int{4, 5})); !errors.Is(err, ErrOverflow) {
+		t.Errorf("PushSeq() over capacity error = %v, want ErrOverflow", err)
+	}
+	if size := s.Size(); size != 4 {
+		t.Errorf("Size() after rejected PushSeq() = %d, want 4", size)
+	}
+
+	sharded := NewSharded[int](2)
+	if err := sharded.PushSeq(slices.Values([]int{1, 2, 3})); err != nil || sharded.Size() != 3 {
+		t.Errorf("sharded PushSeq() = %v, size %d, want nil, 3", err, sharded.Size())
+	}
+}
+
+func TestPopWhile(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3, 4, 5}))
+
+	var got []int
+	n := s.PopWhile(func(v int) bool {
+		got = append(got, v)
+		return v != 3
+	})
+	if n != 3 || !slices.Equal(got, []int{5, 4, 3}) {
+		t.Errorf("PopWhile() = %d, visited %v, want 3, [5 4 3]", n, got)
+	}
+	if gotItems := s.ToSlice(); !slices.Equal(gotItems, []int{1, 2}) {
+		t.Errorf("ToSlice() after PopWhile() = %v, want [1 2]", gotItems)
+	}
+
+	// fn may use the stack itself, since it runs outside the lock.
+	n = s.PopWhile(func(v int) bool {
+		if v == 2 {
+			_ = s.Push(10)
+		}
+		return true
+	})
+	if n != 3 || !s.IsEmpty() {
+		t.Errorf("PopWhile(always) = %d, size %d, want 3, 0", n, s.Size())
+	}
+
+	sharded := NewSharded[int](2, WithItems([]int{1, 2, 3}))
+	if n := sharded.PopWhile(func(int) bool { return true }); n != 3 {
+		t.Errorf("sharded PopWhile() = %d, want 3", n)
+	}
+}
@@ -0,0 +1,146 @@
+package stack
+
+import (
+	"cmp"
+	"slices"
+	"unsafe"
+)
+
+// lockOrder returns the concrete stacks backing stacks, without duplicates, in
+// the order in which they must be locked together to avoid deadlock. Stacks are
+// ordered by address, and the shards of a sharded stack follow one another in
+// index order, the same order in which its own methods lock them. Returns false
+// if any of stacks is not implemented by this package.
+func lockOrder[T any](stacks ...Stack[T]) ([]*stack[T], bool) {
+	owners := make([]Stack[T], 0, len(stacks))
+	for _, s := range stacks {
+		switch s.(type) {
+		case *stack[T], *sharded[T]:
+			owners = append(owners, s)
+		default:
+			return nil, false
+		}
+	}
+
+	slices.SortFunc(owners, func(a, b Stack[T]) int {
+		return cmp.Compare(address(a), address(b))
+	})
+	owners = slices.Compact(owners)
+
+	var result []*stack[T]
+	for _, s := range owners {
+		switch s := s.(type) {
+		case *stack[T]:
+			result = append(result, s)
+		case *sharded[T]:
+			result = append(result, s.shards...)
+		}
+	}
+
+	return result, true
+}
+
+// address returns the address of the concrete stack behind s.
+func address[T any](s Stack[T]) uintptr {
+	switch s := s.(type) {
+	case *stack[T]:
+		return uintptr(unsafe.Pointer(s))
+	case *sharded[T]:
+		return uintptr(unsafe.Pointer(s))
+	default:
+		return 0
+	}
+}
+
+// LockAll write-locks every one of stacks and returns a function that unlocks
+// them again. The locks are acquired in a deterministic order, by address,
+// which is the same order used by the functions and methods of this package
+// that lock several stacks at once, such as DrainTo, Equal and Merge. Any
+// number of goroutines may therefore lock overlapping groups of stacks without
+// risking deadlock. Stacks listed more than once are locked once.
+//
+// While the locks are held no other goroutine can read or modify the stacks.
+// The locks are not reentrant, so the caller must not call methods on the
+// locked stacks either until the returned function has been called; LockAll is
+// meant for pausing a group of stacks together, for example while related
+// state elsewhere is updated.
+//
+// Example:
+//
+//	unlock := stack.LockAll(inbox, outbox)
+//	cursor.Advance() // Neither stack changes meanwhile
+//	unlock()
+//
+// Panics if any of stacks is not implemented by this package.
+func LockAll[T any](stacks ...Stack[T]) (unlock func()) {
+	locks, ok := lockOrder(stacks...)
+	if !ok {
+		panic("cannot lock a foreign stack implementation")
+	}
+
+	return lockStacks(locks)
+}
+
+// rlockStacks acquires the read locks of stacks, which must come from lockOrder,
+// and returns a function that releases them.
+func rlockStacks[T any](stacks []*stack[T]) func() {
+	for _, s := range stacks {
+		s.rlock()
+	}
+
+	return func() {
+		for _, s := range stacks {
+			s.runlock()
+		}
+	}
+}
+
+// lockStacks acquires the write locks of stacks, which must come from lockOrder,
+// and returns a function that releases them and then runs any callbacks for
+// changes made while they were held.
+func lockStacks[T any](stacks []*stack[T]) func() {
+	for _, s := range stacks {
+		s.lock()
+	}
+
+	return func() {
+		var events []hookEvents[T]
+		for _, s := range stacks {
+			if ev := s.takeEvents(); ev.pending() {
+				events = append(events, ev)
+			}
+			s.unlock()
+		}
+
+		for _, ev := range events {
+			ev.run()
+		}
+	}
+}
+
+// lockedItems returns the items of s, bottom to top, without acquiring any lock.
+// Callers must hold the locks of every stack returned by lockOrder for s, and
+// must not modify the result.
+func lockedItems[T any](s Stack[T]) []T {
+	switch s := s.(type) {
+	case *stack[T]:
+		return s.items
+	case *sharded[T]:
+		return s.gather()
+	default:
+		panic("cannot access items of a foreign stack implementation")
+	}
+}
+
+// lockedPeek is like Peek, but does not acquire any lock. Callers must hold the
+// locks of every stack returned by lockOrder for s.
+func lockedPeek[T any](s Stack[T]) (T, error) {
+	switch s := s.(type) {
+	case *stack[T]:
+		return s.peek()
+	case *sharded[T]:
+		return s.peek()
+	default:
+		panic("cannot access items of a foreign stack implementation")
+	}
+}
@@ -0,0 +1,88 @@
+package stack
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockAll(t *testing.T) {
+	a := New[int]()
+	b := NewSharded[int](4)
+
+	unlock := LockAll(a, b, a)
+
+	pushed := make(chan struct{})
+	go func() {
+		_ = a.Push(1)
+		_ = b.PushMany(2, 3)
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("Push() completed while the stacks were locked")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlock()
+	<-pushed
+	if a.Size() != 1 || b.Size() != 2 {
+		t.Errorf("sizes after unlock = %d, %d, want 1, 2", a.Size(), b.Size())
+	}
+}
+
+func TestLockAllOrdering(t *testing.T) {
+	a := NewSharded[int](4)
+	b := NewSharded[int](4)
+	c := New[int]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			LockAll(a, b, c)()
+		}()
+		go func() {
+			defer wg.Done()
+			LockAll(c, b, a)()
+		}()
+		go func() {
+			defer wg.Done()
+			_ = a.PushMany(1, 2)
+			a.Clear()
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = b.DrainTo(a, -1)
+			_ = Equal(a, b)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("concurrent LockAll calls deadlocked")
+	}
+}
+
+func TestLockAllForeign(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("LockAll() with a foreign stack did not panic")
+		}
+	}()
+	LockAll(New[int](), foreignStack{New[int]()})
+}
+
+// foreignStack is a Stack implemented outside this package's concrete types.
+type foreignStack struct {
+	Stack[int]
+}
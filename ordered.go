@@ -0,0 +1,117 @@
+package stack
+
+import (
+	"cmp"
+)
+
+// OrderedStack is the type returned by NewOrdered. Min and Max are now part of
+// Stack, so it adds no methods of its own and is kept only for compatibility
+// with code written against it; new code can use Stack directly. Stacks
+// returned by NewOrdered always track their minimum and maximum items, so Min
+// and Max never return ErrNoComparator.
+type OrderedStack[T cmp.Ordered] interface {
+	Stack[T]
+}
+
+// NewOrdered creates a new stack of ordered items that tracks its minimum and
+// maximum items. It accepts the same options as New. Items are compared with
+// cmp.Less unless WithComparator supplies another ordering.
+//
+// The tracking keeps an auxiliary running minimum and maximum for every item,
+// so an ordered stack uses roughly three times the memory of a plain stack.
+//
+// Example:
+//
+//	s := stack.NewOrdered[int](stack.WithItems([]int{3, 1, 2}))
+//	lo, _ := s.Min() // returns 1
+//	hi, _ := s.Max() // returns 3
+func NewOrdered[T cmp.Ordered](opts ...Option[T]) OrderedStack[T] {
+	s := newStack(opts...)
+	if s.less == nil {
+		s.less = cmp.Less[T]
+		s.reindex()
+	}
+	s.startReporting()
+
+	return s
+}
+
+func (s *stack[T]) Min() (T, error) {
+	s.rlock()
+	defer s.runlock()
+
+	if s.less == nil {
+		var zero T
+		return zero, ErrNoComparator
+	}
+
+	sz := len(s.mins)
+	if sz == 0 {
+		var zero T
+		return zero, ErrUnderflow
+	}
+
+	return s.mins[sz-1], nil
+}
+
+func (s *stack[T]) Max() (T, error) {
+	s.rlock()
+	defer s.runlock()
+
+	if s.less == nil {
+		var zero T
+		return zero, ErrNoComparator
+	}
+
+	sz := len(s.maxs)
+	if sz == 0 {
+		var zero T
+		return zero, ErrUnderflow
+	}
+
+	return s.maxs[sz-1], nil
+}
+
+func (s *sharded[T]) Min() (T, error) {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return s.extreme(func(sh *stack[T]) []T { return sh.mins }, false)
+}
+
+func (s *sharded[T]) Max() (T, error) {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return s.extreme(func(sh *stack[T]) []T { return sh.maxs }, true)
+}
+
+// extreme combines the running minimum or maximum of every non-empty shard,
+// read from the slice returned by running. If largest is set, it returns the
+// greatest of them, otherwise the smallest. Callers must hold every shard's lock.
+func (s *sharded[T]) extreme(running func(sh *stack[T]) []T, largest bool) (T, error) {
+	var result T
+	less := s.shards[0].less
+	if less == nil {
+		return result, ErrNoComparator
+	}
+
+	found := false
+	for _, sh := range s.shards {
+		vals := running(sh)
+		if len(vals) == 0 {
+			continue
+		}
+
+		val := vals[len(vals)-1]
+		if !found || (largest && less(result, val)) || (!largest && less(val, result)) {
+			result = val
+		}
+		found = true
+	}
+	if !found {
+		return result, ErrUnderflow
+	}
+
+	return result, nil
+}
@@ -0,0 +1,167 @@
+package stack
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestNewOrdered(t *testing.T) {
+	t.Run("empty stack", func(t *testing.T) {
+		s := NewOrdered[int]()
+		if _, err := s.Min(); !errors.Is(err, ErrUnderflow) {
+			t.Errorf("Min() on empty stack error = %v, want ErrUnderflow", err)
+		}
+		if _, err := s.Max(); !errors.Is(err, ErrUnderflow) {
+			t.Errorf("Max() on empty stack error = %v, want ErrUnderflow", err)
+		}
+	})
+
+	t.Run("tracks push and pop", func(t *testing.T) {
+		s := NewOrdered[int]()
+
+		steps := []struct {
+			push             int
+			wantMin, wantMax int
+		}{
+			{push: 5, wantMin: 5, wantMax: 5},
+			{push: 3, wantMin: 3, wantMax: 5},
+			{push: 7, wantMin: 3, wantMax: 7},
+			{push: 3, wantMin: 3, wantMax: 7},
+			{push: 1, wantMin: 1, wantMax: 7},
+		}
+
+		for _, step := range steps {
+			_ = s.Push(step.push)
+			if lo, _ := s.Min(); lo != step.wantMin {
+				t.Errorf("Min() after Push(%d) = %d, want %d", step.push, lo, step.wantMin)
+			}
+			if hi, _ := s.Max(); hi != step.wantMax {
+				t.Errorf("Max() after Push(%d) = %d, want %d", step.push, hi, step.wantMax)
+			}
+		}
+
+		for i := len(steps) - 1; i > 0; i-- {
+			_, _ = s.Pop()
+			want := steps[i-1]
+			if lo, _ := s.Min(); lo != want.wantMin {
+				t.Errorf("Min() after popping %d = %d, want %d", steps[i].push, lo, want.wantMin)
+			}
+			if hi, _ := s.Max(); hi != want.wantMax {
+				t.Errorf("Max() after popping %d = %d, want %d", steps[i].push, hi, want.wantMax)
+			}
+		}
+	})
+
+	t.Run("seeded items", func(t *testing.T) {
+		s := NewOrdered[string](WithItems([]string{"b", "a", "c"}))
+		if lo, _ := s.Min(); lo != "a" {
+			t.Errorf("Min() = %q, want %q", lo, "a")
+		}
+		if hi, _ := s.Max(); hi != "c" {
+			t.Errorf("Max() = %q, want %q", hi, "c")
+		}
+	})
+
+	t.Run("bulk changes", func(t *testing.T) {
+		s := NewOrdered[int](WithItems([]int{1, 9, 5, 4}))
+
+		if _, err := s.SetCapacity(2); err != nil {
+			t.Fatalf("SetCapacity(2) error = %v, want nil", err)
+		}
+		if lo, _ := s.Min(); lo != 4 {
+			t.Errorf("Min() after truncation = %d, want 4", lo)
+		}
+		if hi, _ := s.Max(); hi != 5 {
+			t.Errorf("Max() after truncation = %d, want 5", hi)
+		}
+
+		s.Clear()
+		if _, err := s.Min(); !errors.Is(err, ErrUnderflow) {
+			t.Errorf("Min() after Clear() error = %v, want ErrUnderflow", err)
+		}
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		s := NewOrdered[int](WithItems([]int{2, 1, 3}))
+		c := s.Clone().(OrderedStack[int])
+
+		_ = c.Push(0)
+		if lo, _ := c.Min(); lo != 0 {
+			t.Errorf("clone Min() = %d, want 0", lo)
+		}
+		if lo, _ := s.Min(); lo != 1 {
+			t.Errorf("original Min() after pushing to clone = %d, want 1", lo)
+		}
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		s := NewOrdered[int]()
+		const numGoroutines = 50
+		const numOperations = 100
+
+		var wg sync.WaitGroup
+		for i := 0; i < numGoroutines; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				for j := 0; j < numOperations; j++ {
+					_ = s.Push(id*numOperations + j)
+					_, _ = s.Min()
+					_, _ = s.Max()
+					if j%2 == 0 {
+						_, _ = s.Pop()
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		items := s.ToSlice()
+		wantMin, wantMax := items[0], items[0]
+		for _, v := range items {
+			wantMin = min(wantMin, v)
+			wantMax = max(wantMax, v)
+		}
+		if lo, _ := s.Min(); lo != wantMin {
+			t.Errorf("Min() after concurrent access = %d, want %d", lo, wantMin)
+		}
+		if hi, _ := s.Max(); hi != wantMax {
+			t.Errorf("Max() after concurrent access = %d, want %d", hi, wantMax)
+		}
+	})
+}
+
+func TestWithComparator(t *testing.T) {
+	type task struct {
+		name     string
+		priority int
+	}
+	byPriority := func(a, b task) bool { return a.priority < b.priority }
+
+	s := New[task](WithComparator(byPriority), WithItems([]task{{"b", 2}, {"a", 1}, {"c", 3}}))
+	if lo, err := s.Min(); err != nil || lo.name != "a" {
+		t.Errorf("Min() = %v, %v, want a, nil", lo, err)
+	}
+	if hi, err := s.Max(); err != nil || hi.name != "c" {
+		t.Errorf("Max() = %v, %v, want c, nil", hi, err)
+	}
+
+	_, _ = s.Pop()
+	if hi, _ := s.Max(); hi.name != "b" {
+		t.Errorf("Max() after Pop() = %v, want b", hi)
+	}
+
+	reversed := NewOrdered[int](WithComparator(func(a, b int) bool { return a > b }), WithItems([]int{1, 2}))
+	if lo, _ := reversed.Min(); lo != 2 {
+		t.Errorf("Min() with reversed comparator = %d, want 2", lo)
+	}
+
+	plain := New[int](WithItems([]int{1}))
+	if _, err := plain.Min(); !errors.Is(err, ErrNoComparator) {
+		t.Errorf("Min() without comparator error = %v, want ErrNoComparator", err)
+	}
+	if _, err := plain.Max(); !errors.Is(err, ErrNoComparator) {
+		t.Errorf("Max() without comparator error = %v, want ErrNoComparator", err)
+	}
+}
@@ -0,0 +1,121 @@
+package stack
+
+import (
+	"iter"
+)
+
+// PersistentStack is an immutable stack. Push and Pop leave the stack they are
+// called on unchanged and return a new version instead, so every version remains
+// valid and can be branched from independently.
+//
+// Versions share their common items through a linked structure: pushing onto a
+// stack allocates a single node for the new item, and popping allocates nothing.
+// Because versions are never modified, they are safe for concurrent use without
+// any locking.
+type PersistentStack[T any] interface {
+	// Push returns a new version of the stack with val on top.
+	Push(val T) PersistentStack[T]
+
+	// Pop returns the top item and a new version of the stack without it.
+	// Returns ErrUnderflow and the unchanged stack if the stack is empty.
+	Pop() (T, PersistentStack[T], error)
+
+	// Peek returns the top item.
+	// Returns ErrUnderflow if the stack is empty.
+	Peek() (T, error)
+
+	// Size returns the number of items in this version of the stack.
+	Size() int
+
+	// IsEmpty reports whether this version of the stack contains no items.
+	IsEmpty() bool
+
+	// ToSlice returns a copy of the items ordered from bottom to top.
+	ToSlice() []T
+
+	// All returns an iterator over the items from top to bottom (LIFO order).
+	All() iter.Seq[T]
+}
+
+// NewPersistent creates an empty persistent stack.
+//
+// Push and Pop are O(1) and never copy existing items, which makes branching
+// cheap. In exchange, every push allocates a node and items are not stored
+// contiguously, so a persistent stack uses more memory and iterates more slowly
+// than the slice-backed stack returned by New. It also has no capacity limit.
+// Prefer New unless old versions need to stay valid after a change.
+//
+// Example:
+//
+//	empty := stack.NewPersistent[int]()
+//	one := empty.Push(1)
+//	a := one.Push(2) // [1 2]
+//	b := one.Push(3) // [1 3], sharing 1 with a
+//	val, rest, _ := a.Pop() // returns 2 and a stack equal to one
+func NewPersistent[T any]() PersistentStack[T] {
+	return &persistent[T]{}
+}
+
+// persistent is a node of a persistent stack. Each node is the top of the stack
+// version it represents, with next pointing to the version below. The empty
+// stack is a node with size zero.
+type persistent[T any] struct {
+	val  T
+	next *persistent[T]
+	size int
+}
+
+func (p *persistent[T]) Push(val T) PersistentStack[T] {
+	return &persistent[T]{val: val, next: p, size: p.size + 1}
+}
+
+func (p *persistent[T]) Pop() (T, PersistentStack[T], error) {
+	if p.size == 0 {
+		var zero T
+		return zero, p, ErrUnderflow
+	}
+
+	return p.val, p.next, nil
+}
+
+func (p *persistent[T]) Peek() (T, error) {
+	if p.size == 0 {
+		var zero T
+		return zero, ErrUnderflow
+	}
+
+	return p.val, nil
+}
+
+func (p *persistent[T]) Size() int {
+	return p.size
+}
+
+func (p *persistent[T]) IsEmpty() bool {
+	return p.size == 0
+}
+
+func (p *persistent[T]) ToSlice() []T {
+	result := make([]T, p.size)
+	for n := p; n.size > 0; n = n.next {
+		result[n.size-1] = n.val
+	}
+
+	return result
+}
+
+func (p *persistent[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := p; n.size > 0; n = n.next {
+			if !yield(n.val) {
+				return
+			}
+		}
+	}
+}
+
+// String returns a representation in the same format as the other stacks,
+// for example "Stack[2/∞]: [1 2]".
+func (p *persistent[T]) String() string {
+	return formatStack("", p.ToSlice(), UnlimitedCapacity)
+}
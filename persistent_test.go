@@ -0,0 +1,103 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestPersistent(t *testing.T) {
+	empty := NewPersistent[int]()
+	if !empty.IsEmpty() || empty.Size() != 0 {
+		t.Fatalf("NewPersistent() size = %d, want empty", empty.Size())
+	}
+
+	one := empty.Push(1)
+	a := one.Push(2)
+	b := one.Push(3)
+
+	if got := a.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("a.ToSlice() = %v, want [1 2]", got)
+	}
+	if got := b.ToSlice(); !slices.Equal(got, []int{1, 3}) {
+		t.Errorf("b.ToSlice() = %v, want [1 3]", got)
+	}
+	if got := one.ToSlice(); !slices.Equal(got, []int{1}) {
+		t.Errorf("one.ToSlice() after branching = %v, want [1]", got)
+	}
+	if !empty.IsEmpty() {
+		t.Error("pushing changed the empty stack")
+	}
+
+	val, rest, err := a.Pop()
+	if err != nil || val != 2 {
+		t.Errorf("a.Pop() = %d, %v, want 2, nil", val, err)
+	}
+	if rest != one {
+		t.Error("a.Pop() did not return the version it was pushed onto")
+	}
+	if a.Size() != 2 {
+		t.Errorf("a.Size() after Pop() = %d, want 2", a.Size())
+	}
+
+	if top, err := b.Peek(); err != nil || top != 3 {
+		t.Errorf("b.Peek() = %d, %v, want 3, nil", top, err)
+	}
+	if got := slices.Collect(a.All()); !slices.Equal(got, []int{2, 1}) {
+		t.Errorf("a.All() = %v, want [2 1]", got)
+	}
+	if got := fmt.Sprint(a); got != "Stack[2/∞]: [1 2]" {
+		t.Errorf("String() = %q, want %q", got, "Stack[2/∞]: [1 2]")
+	}
+}
+
+func TestPersistentUnderflow(t *testing.T) {
+	empty := NewPersistent[string]()
+
+	val, rest, err := empty.Pop()
+	if !errors.Is(err, ErrUnderflow) || val != "" || rest != empty {
+		t.Errorf("Pop() on empty = %q, %v, %v, want zero value, same stack, ErrUnderflow", val, rest, err)
+	}
+	if _, err := empty.Peek(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Peek() on empty error = %v, want ErrUnderflow", err)
+	}
+	if got := empty.ToSlice(); got == nil || len(got) != 0 {
+		t.Errorf("ToSlice() on empty = %#v, want empty non-nil slice", got)
+	}
+}
+
+func TestPersistentConcurrent(t *testing.T) {
+	base := NewPersistent[int]().Push(0)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := base
+			for j := range 100 {
+				s = s.Push(i*100 + j)
+			}
+			if s.Size() != 101 {
+				t.Errorf("branch size = %d, want 101", s.Size())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if base.Size() != 1 {
+		t.Errorf("base size = %d, want 1", base.Size())
+	}
+}
+
+func BenchmarkPersistentPushPop(b *testing.B) {
+	s := NewPersistent[int]()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s = s.Push(i)
+		_, s, _ = s.Pop()
+	}
+}
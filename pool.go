@@ -0,0 +1,65 @@
+package stack
+
+import (
+	"sync"
+)
+
+// Pool is a set of empty stacks that can be reused to reduce allocations when
+// many short-lived stacks are needed, such as one per request. It wraps
+// sync.Pool and is safe for concurrent use.
+//
+// Example:
+//
+//	pool := stack.NewPool[int](stack.WithCapacity[int](64))
+//
+//	s := pool.Get()
+//	defer pool.Put(s)
+type Pool[T any] struct {
+	pool     sync.Pool
+	capacity int
+}
+
+// NewPool creates a pool whose stacks are created with opts, applied as for New.
+// Stacks returned by Get are always empty, even if opts include WithItems.
+func NewPool[T any](opts ...Option[T]) *Pool[T] {
+	p := &Pool[T]{}
+	p.pool.New = func() any {
+		s := newStack(opts...)
+		s.clear()
+		return Stack[T](s)
+	}
+
+	// The first stack also tells the capacity to reset stacks to in Put, so
+	// pool it rather than build one just to read its capacity.
+	first := p.pool.New().(Stack[T])
+	p.capacity = first.Capacity()
+	p.pool.Put(first)
+
+	return p
+}
+
+// Get returns an empty stack from the pool, creating one if the pool is empty.
+func (p *Pool[T]) Get() Stack[T] {
+	return p.pool.Get().(Stack[T])
+}
+
+// Put clears s and returns it to the pool for reuse by Get. The storage of s is
+// retained, and its capacity and MaxDepth are reset to those of a new stack.
+// The counters reported by Stats carry over between uses.
+//
+// Stacks that cannot be reset, such as closed or frozen stacks, are dropped rather than
+// pooled, so Get never returns one.
+//
+// Put should only be given stacks obtained from Get on the same pool, and s
+// must not be used after it has been put back.
+func (p *Pool[T]) Put(s Stack[T]) {
+	if s == nil {
+		return
+	}
+
+	if err := s.ResetWithCapacity(p.capacity); err != nil {
+		return
+	}
+	s.ResetMaxDepth()
+	p.pool.Put(s)
+}
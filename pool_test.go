@@ -0,0 +1,93 @@
+package stack
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	pool := NewPool[int](WithCapacity[int](4), WithItems([]int{1}))
+
+	s := pool.Get()
+	if !s.IsEmpty() {
+		t.Errorf("Get() returned stack with %d items, want empty", s.Size())
+	}
+	if s.Capacity() != 4 {
+		t.Errorf("Get() Capacity() = %d, want 4", s.Capacity())
+	}
+
+	_ = s.PushMany(1, 2, 3)
+	_, _ = s.SetCapacity(10)
+	pool.Put(s)
+
+	if !s.IsEmpty() {
+		t.Errorf("stack holds %d items after Put(), want empty", s.Size())
+	}
+	if s.Capacity() != 4 {
+		t.Errorf("Capacity() after Put() = %d, want 4", s.Capacity())
+	}
+	if s.MaxDepth() != 0 {
+		t.Errorf("MaxDepth() after Put() = %d, want 0", s.MaxDepth())
+	}
+
+	pool.Put(nil) // must not panic
+}
+
+func TestPoolDropsSealed(t *testing.T) {
+	pool := NewPool[int]()
+
+	s := pool.Get()
+	_ = s.Push(1)
+	s.Freeze()
+	pool.Put(s)
+
+	got := pool.Get()
+	if got == s {
+		t.Fatal("Get() returned a frozen stack put back with Put()")
+	}
+	if err := got.Push(1); err != nil {
+		t.Errorf("Push() on stack from Get() = %v, want nil", err)
+	}
+
+	_ = got.Close()
+	pool.Put(got)
+
+	if again := pool.Get(); again == got {
+		t.Error("Get() returned a closed stack put back with Put()")
+	}
+}
+
+func TestPoolConcurrent(t *testing.T) {
+	pool := NewPool[int]()
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				s := pool.Get()
+				if !s.IsEmpty() {
+					t.Error("Get() returned a non-empty stack")
+					return
+				}
+				_ = s.Push(i)
+				pool.Put(s)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkPoolGetPut(b *testing.B) {
+	pool := NewPool[int](WithCapacity[int](64))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := pool.Get()
+		for j := range 64 {
+			_ = s.Push(j)
+		}
+		pool.Put(s)
+	}
+}
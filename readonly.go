@@ -0,0 +1,130 @@
+package stack
+
+import (
+	"fmt"
+	"iter"
+)
+
+// ReadOnly is a view of a stack that allows its items to be observed but not
+// modified. Views are created with AsReadOnly and share the items and lock of
+// the stack they were created from, so each read is consistent with concurrent
+// writes made through the stack itself.
+//
+// A view cannot be converted back into a Stack, so it can be handed to code that
+// must not push, pop or clear.
+type ReadOnly[T any] interface {
+	// Size returns the current number of items in the stack.
+	Size() int
+
+	// Capacity returns the maximum number of items the stack can hold,
+	// or UnlimitedCapacity if the stack has no size limit.
+	Capacity() int
+
+	// IsEmpty reports whether the stack contains no items.
+	IsEmpty() bool
+
+	// IsFull reports whether the stack has reached its capacity.
+	IsFull() bool
+
+	// Peek returns the top item without removing it from the stack.
+	// Returns ErrUnderflow if the stack is empty.
+	Peek() (T, error)
+
+	// PeekN returns the top n items, ordered from top to bottom.
+	// Returns ErrUnderflow if the stack holds fewer than n items, or
+	// ErrIndexOutOfRange if n is negative.
+	PeekN(n int) ([]T, error)
+
+	// ToSlice returns a copy of the items ordered from bottom to top.
+	ToSlice() []T
+
+	// Clone returns an independent, modifiable copy of the stack.
+	Clone() Stack[T]
+
+	// All returns an iterator over a snapshot of the items from top to bottom.
+	All() iter.Seq[T]
+
+	// ForEach calls fn for each item from bottom to top while holding the read lock.
+	ForEach(fn func(T))
+
+	// ForEachReverse calls fn for each item from top to bottom while holding the read lock.
+	ForEachReverse(fn func(T))
+
+	// Stats returns the cumulative counters of the stack.
+	Stats() Stats
+
+	// MaxDepth returns the largest size of the stack since it was created or
+	// ResetMaxDepth was last called.
+	MaxDepth() int
+
+	// RemainingCapacity returns how many more items can be pushed before the
+	// stack is full, or UnlimitedCapacity for an unbounded stack.
+	RemainingCapacity() int
+
+	// PeekAt returns the item at the given depth, where depth 0 is the top item.
+	PeekAt(depth int) (T, error)
+
+	// CopyInto copies the items, ordered from bottom to top, into dst and
+	// returns the number copied.
+	CopyInto(dst []T) int
+
+	// Min and Max return the smallest and largest items on the stack.
+	Min() (T, error)
+	Max() (T, error)
+
+	// Name returns the name set with WithName, or "" if the stack is unnamed.
+	Name() string
+
+	// Find returns the topmost item for which pred returns true and reports
+	// whether one was found.
+	Find(pred func(T) bool) (T, bool)
+
+	// Utilization returns the size of the stack as a fraction of its capacity,
+	// or -1 for an unbounded stack.
+	Utilization() float64
+
+	// AppendTo appends the items, ordered from bottom to top, to dst and
+	// returns the extended slice.
+	AppendTo(dst []T) []T
+
+	// String returns the same representation as the stack's String method.
+	String() string
+}
+
+// readOnly implements ReadOnly by forwarding the non-mutating methods of s.
+// Holding s in an unexported field prevents callers from reaching its other methods.
+type readOnly[T any] struct {
+	s Stack[T]
+}
+
+func (s *stack[T]) AsReadOnly() ReadOnly[T] {
+	return readOnly[T]{s: s}
+}
+
+func (s *sharded[T]) AsReadOnly() ReadOnly[T] {
+	return readOnly[T]{s: s}
+}
+
+func (r readOnly[T]) Size() int                        { return r.s.Size() }
+func (r readOnly[T]) Capacity() int                    { return r.s.Capacity() }
+func (r readOnly[T]) IsEmpty() bool                    { return r.s.IsEmpty() }
+func (r readOnly[T]) IsFull() bool                     { return r.s.IsFull() }
+func (r readOnly[T]) Peek() (T, error)                 { return r.s.Peek() }
+func (r readOnly[T]) PeekN(n int) ([]T, error)         { return r.s.PeekN(n) }
+func (r readOnly[T]) ToSlice() []T                     { return r.s.ToSlice() }
+func (r readOnly[T]) Clone() Stack[T]                  { return r.s.Clone() }
+func (r readOnly[T]) All() iter.Seq[T]                 { return r.s.All() }
+func (r readOnly[T]) ForEach(fn func(T))               { r.s.ForEach(fn) }
+func (r readOnly[T]) ForEachReverse(fn func(T))        { r.s.ForEachReverse(fn) }
+func (r readOnly[T]) String() string                   { return fmt.Sprint(r.s) }
+func (r readOnly[T]) PeekAt(depth int) (T, error)      { return r.s.PeekAt(depth) }
+func (r readOnly[T]) RemainingCapacity() int           { return r.s.RemainingCapacity() }
+func (r readOnly[T]) Stats() Stats                     { return r.s.Stats() }
+func (r readOnly[T]) MaxDepth() int                    { return r.s.MaxDepth() }
+func (r readOnly[T]) CopyInto(dst []T) int             { return r.s.CopyInto(dst) }
+func (r readOnly[T]) Min() (T, error)                  { return r.s.Min() }
+func (r readOnly[T]) Max() (T, error)                  { return r.s.Max() }
+func (r readOnly[T]) Name() string                     { return r.s.Name() }
+func (r readOnly[T]) Find(pred func(T) bool) (T, bool) { return r.s.Find(pred) }
+func (r readOnly[T]) Utilization() float64             { return r.s.Utilization() }
+func (r readOnly[T]) AppendTo(dst []T) []T             { return r.s.AppendTo(dst) }
@@ -0,0 +1,88 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAsReadOnly(t *testing.T) {
+	for name, s := range map[string]Stack[int]{
+		"plain":   New[int](WithCapacity[int](3), WithItems([]int{1, 2})),
+		"sharded": NewSharded[int](2, WithCapacity[int](3), WithItems([]int{1, 2})),
+	} {
+		t.Run(name, func(t *testing.T) {
+			view := s.AsReadOnly()
+
+			if _, ok := view.(Stack[int]); ok {
+				t.Fatal("ReadOnly view can be converted back to a Stack")
+			}
+			if view.Size() != 2 || view.Capacity() != 3 || view.IsEmpty() || view.IsFull() {
+				t.Errorf("unexpected view state %v", view)
+			}
+
+			_ = s.Push(3)
+			want, _ := s.Peek()
+			if top, err := view.Peek(); err != nil || top != want {
+				t.Errorf("view Peek() = %d, %v, want %d, nil", top, err, want)
+			}
+			if !view.IsFull() {
+				t.Error("view IsFull() = false after filling the stack")
+			}
+			if got := view.ToSlice(); !slices.Equal(got, s.ToSlice()) {
+				t.Errorf("view ToSlice() = %v, want %v", got, s.ToSlice())
+			}
+			if got, want := slices.Collect(view.All()), slices.Collect(s.All()); !slices.Equal(got, want) {
+				t.Errorf("view All() = %v, want %v", got, want)
+			}
+			if view.String() != s.(interface{ String() string }).String() {
+				t.Errorf("view String() = %q, want %q", view.String(), s)
+			}
+
+			if view.Stats() != s.Stats() || view.MaxDepth() != s.MaxDepth() {
+				t.Errorf("view Stats() = %+v and MaxDepth() = %d, want %+v and %d", view.Stats(), view.MaxDepth(), s.Stats(), s.MaxDepth())
+			}
+
+			if got, want := view.RemainingCapacity(), s.RemainingCapacity(); got != want {
+				t.Errorf("view RemainingCapacity() = %d, want %d", got, want)
+			}
+
+			if got, err := view.PeekAt(2); err != nil || got != s.ToSlice()[0] {
+				t.Errorf("view PeekAt(2) = %d, %v, want %d, nil", got, err, s.ToSlice()[0])
+			}
+
+			if buf := make([]int, 3); view.CopyInto(buf) != 3 || !slices.Equal(buf, s.ToSlice()) {
+				t.Errorf("view CopyInto() copied %v, want %v", buf, s.ToSlice())
+			}
+
+			if _, err := view.Min(); !errors.Is(err, ErrNoComparator) {
+				t.Errorf("view Min() error = %v, want ErrNoComparator", err)
+			}
+			if _, err := view.Max(); !errors.Is(err, ErrNoComparator) {
+				t.Errorf("view Max() error = %v, want ErrNoComparator", err)
+			}
+
+			if got := view.Name(); got != s.Name() {
+				t.Errorf("view Name() = %q, want %q", got, s.Name())
+			}
+
+			if got, ok := view.Find(func(v int) bool { return v == 3 }); !ok || got != 3 {
+				t.Errorf("view Find() = %d, %t, want 3, true", got, ok)
+			}
+
+			if got := view.Utilization(); got != 1 {
+				t.Errorf("view Utilization() = %v, want 1", got)
+			}
+
+			if got := view.AppendTo([]int{0}); !slices.Equal(got, append([]int{0}, s.ToSlice()...)) {
+				t.Errorf("view AppendTo() = %v, want [0] followed by %v", got, s.ToSlice())
+			}
+
+			clone := view.Clone()
+			_, _ = clone.Pop()
+			if view.Size() != 3 {
+				t.Error("modifying a clone of the view changed the stack")
+			}
+		})
+	}
+}
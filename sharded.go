@@ -0,0 +1,1150 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NewSharded creates a stack that spreads its items across the given number of
+// independently locked shards, reducing lock contention when many goroutines
+// push and pop concurrently.
+//
+// Options are applied as for New. A capacity set with WithCapacity limits the
+// total number of items across all shards and is divided as evenly as possible
+// between them.
+//
+// IMPORTANT: a sharded stack does not preserve global LIFO order. Each shard is
+// LIFO on its own, but Push and Pop pick shards at random, so Pop may
+// return the top item of any non-empty shard. Operations that view the stack as
+// a whole (Peek, PeekN, ToSlice, All) treat the shards as if they were stacked
+// on top of each other in order, which does not predict what Pop returns next.
+// Use New when ordering matters.
+//
+// WithMaxBytes is not supported, because a byte budget cannot be enforced
+// across independently locked shards without giving up the reduced contention.
+//
+// Example:
+//
+//	s := stack.NewSharded[int](16, stack.WithCapacity[int](1024))
+//
+// WithOnEmpty is not supported either, because the moment the last shard is
+// emptied cannot be observed without locking every shard on every change.
+//
+// Panics if shards < 1 or if WithMaxBytes, WithUniqueness or WithOnEmpty is
+// given.
+func NewSharded[T any](shards int, opts ...Option[T]) Stack[T] {
+	if shards < 1 {
+		panic("cannot create a sharded stack with fewer than one shard")
+	}
+
+	base := newStack(opts...)
+	if base.sizeOf != nil {
+		panic("cannot bound a sharded stack by bytes")
+	}
+	if base.unique != nil {
+		panic("cannot enforce uniqueness on a sharded stack")
+	}
+	if base.onEmpty != nil {
+		panic("cannot report when a sharded stack empties")
+	}
+
+	s := &sharded[T]{
+		name:        base.name,
+		capacity:    base.capacity,
+		shards:      make([]*stack[T], shards),
+		reportEvery: base.reportEvery,
+		report:      base.report,
+	}
+	for i := range s.shards {
+		s.shards[i] = base.emptyCopy()
+		s.shards[i].capacity = shardCapacity(s.capacity, i, shards)
+		s.shards[i].fix()
+		s.shards[i].grow(shardCapacity(base.initialCapacity, i, shards))
+	}
+	s.scatter(base.items)
+	s.startReporting()
+
+	return s
+}
+
+type sharded[T any] struct {
+	name string
+
+	// capacity is the total capacity across all shards. It is only modified
+	// while every shard is write-locked.
+	capacity int
+	shards   []*stack[T]
+
+	// mu guards changed, which wakes goroutines blocked in BlockingPop and
+	// BlockingPush. waiters counts those goroutines so that Push and Pop can
+	// skip the wake-up entirely when nobody is waiting.
+	mu      sync.Mutex
+	changed chan struct{}
+	waiters atomic.Int32
+
+	// stats counts the overflows and underflows of the sharded stack as a
+	// whole. Pushes, pops and sizes are counted by the shards themselves.
+	stats counters
+
+	// closed is set by Close, together with the closed flag of every shard.
+	closed atomic.Bool
+
+	// frozen is set by Freeze, together with the frozen flag of every shard.
+	frozen atomic.Bool
+
+	// report is called with Stats every reportEvery by the goroutine started
+	// by startReporting, until Close closes done. See WithMetricsReporter.
+	reportEvery time.Duration
+	report      func(Stats)
+	done        chan struct{}
+}
+
+// sealed returns ErrClosed if the stack has been closed, ErrFrozen if it has
+// been frozen, and nil if items may still be pushed and popped.
+func (s *sharded[T]) sealed() error {
+	switch {
+	case s.closed.Load():
+		return ErrClosed
+	case s.frozen.Load():
+		return ErrFrozen
+	default:
+		return nil
+	}
+}
+
+// shardCapacity returns the capacity of shard i when capacity is divided between n shards.
+func shardCapacity(capacity, i, n int) int {
+	if capacity < 0 {
+		return UnlimitedCapacity
+	}
+
+	c := capacity / n
+	if i < capacity%n {
+		c++
+	}
+
+	return c
+}
+
+// start returns a random shard at which to begin scanning. Starting at a
+// random shard spreads concurrent callers across shards without any shared state.
+func (s *sharded[T]) start() int {
+	return rand.IntN(len(s.shards))
+}
+
+// lockAll acquires the write lock of every shard in index order.
+func (s *sharded[T]) lockAll() {
+	for _, sh := range s.shards {
+		sh.lock()
+	}
+}
+
+// unlockAll releases the locks acquired by lockAll, then runs any callbacks
+// for changes made while they were held.
+func (s *sharded[T]) unlockAll() {
+	var events []hookEvents[T]
+	for _, sh := range s.shards {
+		if ev := sh.takeEvents(); ev.pending() {
+			events = append(events, ev)
+		}
+		sh.unlock()
+	}
+
+	for _, ev := range events {
+		ev.run()
+	}
+}
+
+// rlockAll acquires the read lock of every shard in index order.
+func (s *sharded[T]) rlockAll() {
+	for _, sh := range s.shards {
+		sh.rlock()
+	}
+}
+
+// runlockAll releases the locks acquired by rlockAll.
+func (s *sharded[T]) runlockAll() {
+	for _, sh := range s.shards {
+		sh.runlock()
+	}
+}
+
+// size returns the total number of items. Callers must hold every shard's lock.
+func (s *sharded[T]) size() int {
+	n := 0
+	for _, sh := range s.shards {
+		n += len(sh.items)
+	}
+
+	return n
+}
+
+// gather returns the items of all shards, bottom shard first.
+// Callers must hold every shard's lock.
+func (s *sharded[T]) gather() []T {
+	items := make([]T, 0, s.size())
+	for _, sh := range s.shards {
+		items = append(items, sh.items...)
+	}
+
+	return items
+}
+
+// scatter replaces the items of all shards, splitting items into contiguous
+// chunks that are as even as possible. Callers must hold every shard's write
+// lock and ensure items fit within the total capacity.
+func (s *sharded[T]) scatter(items []T) {
+	n, total := len(s.shards), len(items)
+	for i, sh := range s.shards {
+		sz := total / n
+		if i < total%n {
+			sz++
+		}
+
+		sh.clear()
+		for _, val := range items[:sz] {
+			sh.push(val)
+		}
+		sh.trackDepth()
+		sh.broadcast()
+		items = items[sz:]
+	}
+}
+
+// setCapacity changes the total capacity and divides it between the shards.
+// Callers must hold every shard's write lock.
+func (s *sharded[T]) setCapacity(capacity int) {
+	s.capacity = capacity
+	for i, sh := range s.shards {
+		sh.capacity = shardCapacity(capacity, i, len(s.shards))
+		sh.fix()
+	}
+}
+
+// locate returns the shard and index holding the item at the given depth from
+// the top of the combined view used by ToSlice. Callers must hold every shard's
+// lock and ensure depth is less than the total size.
+func (s *sharded[T]) locate(depth int) (*stack[T], int) {
+	for i := len(s.shards) - 1; ; i-- {
+		sh := s.shards[i]
+		if depth < len(sh.items) {
+			return sh, len(sh.items) - 1 - depth
+		}
+		depth -= len(sh.items)
+	}
+}
+
+// notify wakes goroutines blocked in BlockingPop or BlockingPush. It is called
+// after every change, and callers must not hold any locks.
+func (s *sharded[T]) notify() {
+	if s.waiters.Load() == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
+
+// wait calls try until it succeeds, blocking between attempts until the stack
+// changes. Returns ctx.Err() if the context is done first.
+func (s *sharded[T]) wait(ctx context.Context, try func() bool) error {
+	for {
+		if try() {
+			return nil
+		}
+		if err := s.sealed(); err != nil {
+			return err
+		}
+
+		s.waiters.Add(1)
+		s.mu.Lock()
+		if s.changed == nil {
+			s.changed = make(chan struct{})
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		// Re-check after registering as a waiter, in case the stack changed
+		// or was closed before notify could see us.
+		if try() {
+			s.waiters.Add(-1)
+			return nil
+		}
+		if err := s.sealed(); err != nil {
+			s.waiters.Add(-1)
+			return err
+		}
+
+		select {
+		case <-changed:
+			s.waiters.Add(-1)
+		case <-ctx.Done():
+			s.waiters.Add(-1)
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *sharded[T]) Push(val T) error {
+	if ok, size := s.tryPush(val); !ok {
+		if err := s.sealed(); err != nil {
+			return err
+		}
+
+		s.stats.overflows.Add(1)
+		return &OverflowError{Name: s.name, Capacity: s.Capacity(), Size: size}
+	}
+
+	return nil
+}
+
+func (s *sharded[T]) PushMany(vals ...T) error {
+	s.lockAll()
+
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+
+	if s.capacity >= 0 && s.size()+len(vals) > s.capacity {
+		if s.shards[0].policy != OverflowDropOldest || s.capacity == 0 {
+			excess := s.size() + len(vals) - s.capacity
+			s.stats.overflows.Add(1)
+			s.unlockAll()
+			return fmt.Errorf("%w: %d item(s) over capacity", ErrOverflow, excess)
+		}
+	}
+
+	i := s.start()
+	for _, val := range vals {
+		// Prefer a shard with room; once every shard is full, the overflow
+		// policy evicts from the next shard able to hold any items.
+		for j := 0; j < len(s.shards) && !s.shards[i].fits(val); j++ {
+			i = (i + 1) % len(s.shards)
+		}
+		for !s.shards[i].reserve(val) {
+			i = (i + 1) % len(s.shards)
+		}
+		s.shards[i].push(val)
+		s.shards[i].didPush(val)
+		i = (i + 1) % len(s.shards)
+	}
+	for _, sh := range s.shards {
+		sh.broadcast()
+	}
+
+	s.unlockAll()
+	s.notify()
+
+	return nil
+}
+
+func (s *sharded[T]) Pop() (T, error) {
+	val, ok := s.tryPop()
+	if !ok {
+		if err := s.sealed(); err != nil {
+			return val, err
+		}
+		s.stats.underflows.Add(1)
+		return val, &UnderflowError{Name: s.name, Capacity: s.Capacity()}
+	}
+
+	return val, nil
+}
+
+func (s *sharded[T]) Size() int {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return s.size()
+}
+
+func (s *sharded[T]) Peek() (T, error) {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return s.peek()
+}
+
+// peek returns the top item, or an *UnderflowError if the stack is empty.
+// Callers must hold the read locks of every shard.
+func (s *sharded[T]) peek() (T, error) {
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		if items := s.shards[i].items; len(items) > 0 {
+			return items[len(items)-1], nil
+		}
+	}
+
+	s.stats.underflows.Add(1)
+	var zero T
+	return zero, &UnderflowError{Name: s.name, Capacity: s.capacity}
+}
+
+func (s *sharded[T]) Find(pred func(T) bool) (T, bool) {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		items := s.shards[i].items
+		for j := len(items) - 1; j >= 0; j-- {
+			if pred(items[j]) {
+				return items[j], true
+			}
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (s *sharded[T]) Clear() {
+	s.lockAll()
+	if s.sealed() != nil {
+		s.unlockAll()
+		return
+	}
+
+	for _, sh := range s.shards {
+		sh.clear()
+		sh.broadcast()
+	}
+	s.unlockAll()
+
+	s.notify()
+}
+
+func (s *sharded[T]) DrainAll() []T {
+	s.lockAll()
+	if s.frozen.Load() {
+		s.unlockAll()
+		return nil
+	}
+
+	items := s.gather()
+	for _, sh := range s.shards {
+		sh.detach()
+		sh.broadcast()
+	}
+	s.unlockAll()
+
+	s.notify()
+
+	return items
+}
+
+func (s *sharded[T]) RetainFunc(pred func(T) bool) int {
+	s.lockAll()
+	if s.sealed() != nil {
+		s.unlockAll()
+		return 0
+	}
+
+	removed := 0
+	for _, sh := range s.shards {
+		if n := sh.retain(pred); n > 0 {
+			removed += n
+			sh.broadcast()
+		}
+	}
+	s.unlockAll()
+
+	if removed > 0 {
+		s.notify()
+	}
+
+	return removed
+}
+
+func (s *sharded[T]) Name() string {
+	return s.name
+}
+
+func (s *sharded[T]) CompactFunc(eq func(a, b T) bool) int {
+	s.lockAll()
+	if s.sealed() != nil {
+		s.unlockAll()
+		return 0
+	}
+
+	items := s.gather()
+	n := len(items)
+	items = slices.CompactFunc(items, eq)
+	removed := n - len(items)
+	if removed > 0 {
+		s.scatter(items)
+	}
+	s.unlockAll()
+
+	if removed > 0 {
+		s.notify()
+	}
+
+	return removed
+}
+
+func (s *sharded[T]) Capacity() int {
+	// The capacity only changes while every shard is write-locked,
+	// so holding any one shard's read lock is enough.
+	s.shards[0].rlock()
+	defer s.shards[0].runlock()
+
+	return s.capacity
+}
+
+func (s *sharded[T]) IsEmpty() bool {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return s.size() == 0
+}
+
+func (s *sharded[T]) IsFull() bool {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return s.capacity >= 0 && s.size() >= s.capacity
+}
+
+func (s *sharded[T]) ToSlice() []T {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return s.gather()
+}
+
+func (s *sharded[T]) CopyInto(dst []T) int {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	n := 0
+	for _, sh := range s.shards {
+		n += copy(dst[n:], sh.items)
+	}
+
+	return n
+}
+
+func (s *sharded[T]) AppendTo(dst []T) []T {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	dst = slices.Grow(dst, s.size())
+	for _, sh := range s.shards {
+		dst = append(dst, sh.items...)
+	}
+
+	return dst
+}
+
+func (s *sharded[T]) Clone() Stack[T] {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	clone := s.emptyCopy()
+	for i, sh := range s.shards {
+		clone.shards[i].items = append(clone.shards[i].items, sh.items...)
+		clone.shards[i].reindex()
+	}
+
+	return clone
+}
+
+func (s *sharded[T]) DeepClone(clone func(T) T) Stack[T] {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	c := s.emptyCopy()
+	for i, sh := range s.shards {
+		for _, item := range sh.items {
+			c.shards[i].items = append(c.shards[i].items, clone(item))
+		}
+		c.shards[i].reindex()
+	}
+
+	return c
+}
+
+// emptyCopy returns a new sharded stack with the same configuration as s but
+// no items. Callers must hold at least every shard's read lock.
+func (s *sharded[T]) emptyCopy() *sharded[T] {
+	clone := &sharded[T]{
+		name:     s.name,
+		capacity: s.capacity,
+		shards:   make([]*stack[T], len(s.shards)),
+	}
+	for i, sh := range s.shards {
+		clone.shards[i] = sh.emptyCopy()
+	}
+
+	return clone
+}
+
+func (s *sharded[T]) TryPush(val T) bool {
+	if ok, _ := s.tryPush(val); !ok {
+		if s.sealed() == nil {
+			s.stats.overflows.Add(1)
+		}
+		return false
+	}
+
+	return true
+}
+
+// tryPush is TryPush without counting a failure as an overflow. The shards are
+// first tried one at a time, so that pushes to different shards do not
+// contend. If every shard was full when tried, they are tried again with all
+// of them locked, so that the push only fails if the stack as a whole is full
+// or sealed; the number of items it held is then returned as well.
+func (s *sharded[T]) tryPush(val T) (bool, int) {
+	start := s.start()
+	for i := range s.shards {
+		if s.shards[(start+i)%len(s.shards)].tryPush(val) {
+			s.notify()
+			return true, 0
+		}
+	}
+
+	s.lockAll()
+	if s.sealed() == nil {
+		for i := range s.shards {
+			sh := s.shards[(start+i)%len(s.shards)]
+			if sh.reserve(val) {
+				sh.push(val)
+				sh.didPush(val)
+				sh.broadcast()
+				s.unlockAll()
+				s.notify()
+				return true, 0
+			}
+		}
+	}
+	size := s.size()
+	s.unlockAll()
+
+	return false, size
+}
+
+func (s *sharded[T]) TryPop() (T, bool) {
+	val, ok := s.tryPop()
+	if !ok && s.sealed() == nil {
+		s.stats.underflows.Add(1)
+	}
+
+	return val, ok
+}
+
+// tryPop is TryPop without counting a failure as an underflow. Like tryPush,
+// it tries the shards one at a time and then, if they were all empty, once
+// more with all of them locked, so that it only fails if the stack as a whole
+// is empty or sealed.
+func (s *sharded[T]) tryPop() (T, bool) {
+	start := s.start()
+	for i := range s.shards {
+		if val, ok := s.shards[(start+i)%len(s.shards)].tryPop(); ok {
+			s.notify()
+			return val, true
+		}
+	}
+
+	s.lockAll()
+	if s.sealed() == nil {
+		for i := range s.shards {
+			sh := s.shards[(start+i)%len(s.shards)]
+			if len(sh.items) > 0 {
+				val := sh.pop()
+				sh.didPop(val)
+				sh.broadcast()
+				s.unlockAll()
+				s.notify()
+				return val, true
+			}
+		}
+	}
+	s.unlockAll()
+
+	var zero T
+	return zero, false
+}
+
+func (s *sharded[T]) BlockingPop(ctx context.Context) (T, error) {
+	var val T
+	err := s.wait(ctx, func() bool {
+		var ok bool
+		val, ok = s.tryPop()
+		return ok
+	})
+
+	return val, err
+}
+
+func (s *sharded[T]) BlockingPush(ctx context.Context, val T) error {
+	return s.wait(ctx, func() bool {
+		ok, _ := s.tryPush(val)
+		return ok
+	})
+}
+
+func (s *sharded[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		items := s.ToSlice()
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(items[i]) {
+				return
+			}
+		}
+	}
+}
+
+func (s *sharded[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			val, ok := s.tryPop()
+			if !ok || !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+func (s *sharded[T]) PeekN(n int) ([]T, error) {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	sz := s.size()
+	if n < 0 {
+		return nil, fmt.Errorf("%w: count %d, size %d", ErrIndexOutOfRange, n, sz)
+	}
+	if n > sz {
+		s.stats.underflows.Add(1)
+		return nil, &UnderflowError{Name: s.name, Capacity: s.capacity, Size: sz}
+	}
+
+	result := make([]T, 0, n)
+	for i := len(s.shards) - 1; i >= 0 && len(result) < n; i-- {
+		items := s.shards[i].items
+		for j := len(items) - 1; j >= 0 && len(result) < n; j-- {
+			result = append(result, items[j])
+		}
+	}
+
+	return result, nil
+}
+
+func (s *sharded[T]) PeekAt(depth int) (T, error) {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	if sz := s.size(); depth < 0 || depth >= sz {
+		var zero T
+		return zero, fmt.Errorf("%w: depth %d, size %d", ErrIndexOutOfRange, depth, sz)
+	}
+
+	sh, idx := s.locate(depth)
+
+	return sh.items[idx], nil
+}
+
+func (s *sharded[T]) ResetWithCapacity(capacity int) error {
+	if capacity < UnlimitedCapacity {
+		return ErrInvalidCapacity
+	}
+
+	s.lockAll()
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+
+	s.capacity = capacity
+	for i, sh := range s.shards {
+		sh.capacity = shardCapacity(capacity, i, len(s.shards))
+		sh.clear()
+		sh.broadcast()
+	}
+	s.unlockAll()
+
+	s.notify()
+
+	return nil
+}
+
+func (s *sharded[T]) SetCapacity(capacity int) (int, error) {
+	if capacity < UnlimitedCapacity {
+		return 0, ErrInvalidCapacity
+	}
+
+	s.lockAll()
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return 0, err
+	}
+
+	items := s.gather()
+	dropped := 0
+	if capacity >= 0 && len(items) > capacity {
+		dropped = len(items) - capacity
+		items = items[dropped:]
+	}
+
+	s.setCapacity(capacity)
+	s.scatter(items)
+
+	s.unlockAll()
+	s.notify()
+
+	return dropped, nil
+}
+
+func (s *sharded[T]) Grow(n int) {
+	if n < 0 {
+		panic("cannot grow by a negative number of items")
+	}
+
+	per := (n + len(s.shards) - 1) / len(s.shards)
+	for _, sh := range s.shards {
+		sh.Grow(per)
+	}
+}
+
+func (s *sharded[T]) ShrinkToFit() {
+	for _, sh := range s.shards {
+		sh.ShrinkToFit()
+	}
+}
+
+func (s *sharded[T]) ForEach(fn func(T)) {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	for _, sh := range s.shards {
+		for _, item := range sh.items {
+			fn(item)
+		}
+	}
+}
+
+func (s *sharded[T]) ForEachReverse(fn func(T)) {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		items := s.shards[i].items
+		for j := len(items) - 1; j >= 0; j-- {
+			fn(items[j])
+		}
+	}
+}
+
+func (s *sharded[T]) Filter(pred func(T) bool) Stack[T] {
+	s.rlockAll()
+	items := s.gather()
+	result := s.emptyCopy()
+	s.runlockAll()
+
+	kept := items[:0]
+	for _, item := range items {
+		if pred(item) {
+			kept = append(kept, item)
+		}
+	}
+	result.scatter(kept)
+
+	return result
+}
+
+// String returns a readable representation of the stack in the same format
+// as a regular stack, listing the shards' items as ToSlice does.
+func (s *sharded[T]) String() string {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return formatStack(s.name, s.gather(), s.capacity)
+}
+
+func (s *sharded[T]) Reverse() {
+	s.lockAll()
+	if s.sealed() != nil {
+		s.unlockAll()
+		return
+	}
+
+	items := s.gather()
+	slices.Reverse(items)
+	s.scatter(items)
+	s.unlockAll()
+
+	s.notify()
+}
+
+func (s *sharded[T]) Swap() error {
+	s.lockAll()
+	defer s.unlockAll()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if s.size() < 2 {
+		s.stats.underflows.Add(1)
+		return ErrUnderflow
+	}
+
+	a, i := s.locate(0)
+	b, j := s.locate(1)
+	a.items[i], b.items[j] = b.items[j], a.items[i]
+	a.reindex()
+	b.reindex()
+
+	return nil
+}
+
+func (s *sharded[T]) SwapAt(i, j int) error {
+	s.lockAll()
+	defer s.unlockAll()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	sz := s.size()
+	for _, depth := range []int{i, j} {
+		if depth < 0 || depth >= sz {
+			return fmt.Errorf("%w: depth %d, size %d", ErrIndexOutOfRange, depth, sz)
+		}
+	}
+
+	if i != j {
+		a, x := s.locate(i)
+		b, y := s.locate(j)
+		a.items[x], b.items[y] = b.items[y], a.items[x]
+		a.reindex()
+		b.reindex()
+	}
+
+	return nil
+}
+
+func (s *sharded[T]) UpdateTop(fn func(top *T) error) error {
+	s.lockAll()
+	defer s.unlockAll()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if s.size() == 0 {
+		s.stats.underflows.Add(1)
+		return &UnderflowError{Name: s.name, Capacity: s.capacity}
+	}
+
+	top, _ := s.locate(0)
+
+	return top.updateTop(fn)
+}
+
+func (s *sharded[T]) PopUntil(pred func(T) bool) ([]T, error) {
+	s.lockAll()
+	defer s.notify()
+	defer s.unlockAll()
+
+	if err := s.sealed(); err != nil {
+		return nil, err
+	}
+
+	var result []T
+	for s.size() > 0 {
+		sh, i := s.locate(0)
+		if !pred(sh.items[i]) {
+			break
+		}
+
+		val := sh.pop()
+		sh.didPop(val)
+		sh.broadcast()
+		result = append(result, val)
+	}
+
+	return result, nil
+}
+
+func (s *sharded[T]) Dup() error {
+	s.lockAll()
+
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+
+	if s.size() == 0 {
+		s.stats.underflows.Add(1)
+		s.unlockAll()
+		return ErrUnderflow
+	}
+
+	top, i := s.locate(0)
+	val := top.items[i]
+
+	// Keep the copy directly above the original when its shard has room.
+	dst := top
+	for j := 0; j < len(s.shards) && !dst.fits(val); j++ {
+		dst = s.shards[j]
+	}
+	if !dst.fits(val) {
+		dst = top
+		if !dst.reserve(val) {
+			s.stats.overflows.Add(1)
+			s.unlockAll()
+			return ErrOverflow
+		}
+	}
+	dst.push(val)
+	dst.didPush(val)
+	dst.broadcast()
+
+	s.unlockAll()
+	s.notify()
+
+	return nil
+}
+
+func (s *sharded[T]) SplitAt(n int) (bottom, top Stack[T], err error) {
+	s.lockAll()
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return nil, nil, err
+	}
+
+	items := s.gather()
+	if n < 0 {
+		s.unlockAll()
+		return nil, nil, fmt.Errorf("%w: index %d, size %d", ErrIndexOutOfRange, n, len(items))
+	}
+	if n > len(items) {
+		s.stats.underflows.Add(1)
+		s.unlockAll()
+		return nil, nil, ErrUnderflow
+	}
+
+	upper := s.emptyCopy()
+	upper.scatter(items[n:])
+	s.scatter(items[:n])
+	s.unlockAll()
+
+	s.notify()
+
+	return s, upper, nil
+}
+
+func (s *sharded[T]) PopOr(def T) T {
+	if val, ok := s.tryPop(); ok {
+		return val
+	}
+
+	return def
+}
+
+func (s *sharded[T]) PeekOr(def T) T {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	if s.size() == 0 {
+		return def
+	}
+
+	sh, i := s.locate(0)
+	return sh.items[i]
+}
+
+func (s *sharded[T]) RemainingCapacity() int {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	if s.capacity < 0 {
+		return UnlimitedCapacity
+	}
+
+	return s.capacity - s.size()
+}
+
+func (s *sharded[T]) Utilization() float64 {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return utilization(s.size(), s.capacity)
+}
+
+func (s *sharded[T]) PushBottom(val T) error {
+	s.lockAll()
+
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+	if size := s.size(); s.capacity >= 0 && size >= s.capacity {
+		s.stats.overflows.Add(1)
+		s.unlockAll()
+		return &OverflowError{Name: s.name, Capacity: s.capacity, Size: size}
+	}
+
+	s.scatter(slices.Insert(s.gather(), 0, val))
+	s.shards[0].didPush(val)
+	s.unlockAll()
+
+	s.notify()
+
+	return nil
+}
+
+func (s *sharded[T]) PopBottom() (T, error) {
+	s.lockAll()
+	defer s.notify()
+	defer s.unlockAll()
+
+	var zero T
+	if err := s.sealed(); err != nil {
+		return zero, err
+	}
+
+	for _, sh := range s.shards {
+		if len(sh.items) > 0 {
+			result := sh.items[0]
+			sh.evict(1)
+			sh.didPop(result)
+			sh.broadcast()
+			return result, nil
+		}
+	}
+
+	s.stats.underflows.Add(1)
+	return zero, &UnderflowError{Name: s.name, Capacity: s.capacity}
+}
+
+func (s *sharded[T]) Rotate(n int) error {
+	s.lockAll()
+
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+
+	items := s.gather()
+	changed := rotate(items, n)
+	if changed {
+		s.scatter(items)
+	}
+	s.unlockAll()
+
+	if changed {
+		s.notify()
+	}
+
+	return nil
+}
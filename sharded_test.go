@@ -0,0 +1,649 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSharded(t *testing.T) {
+	t.Run("invalid shard count (should panic)", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("NewSharded(0) should panic, but it didn't")
+			}
+		}()
+
+		NewSharded[int](0)
+	})
+
+	t.Run("capacity split across shards", func(t *testing.T) {
+		s := NewSharded[int](4, WithCapacity[int](10))
+		if got := s.Capacity(); got != 10 {
+			t.Errorf("Capacity() = %d, want 10", got)
+		}
+
+		for i := 0; i < 10; i++ {
+			if err := s.Push(i); err != nil {
+				t.Errorf("Push(%d) error = %v, want nil", i, err)
+			}
+		}
+		if !s.IsFull() {
+			t.Error("IsFull() at capacity = false, want true")
+		}
+		if err := s.Push(10); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() beyond capacity error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("more shards than capacity", func(t *testing.T) {
+		s := NewSharded[int](8, WithCapacity[int](3))
+		if err := s.PushMany(1, 2, 3); err != nil {
+			t.Errorf("PushMany() error = %v, want nil", err)
+		}
+		if err := s.Push(4); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() beyond capacity error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("seeded items", func(t *testing.T) {
+		s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+		if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+			t.Errorf("ToSlice() = %v, want [1 2 3 4 5]", got)
+		}
+	})
+}
+
+func TestShardedPushPop(t *testing.T) {
+	s := NewSharded[int](4)
+	for i := 0; i < 100; i++ {
+		_ = s.Push(i)
+	}
+	if size := s.Size(); size != 100 {
+		t.Errorf("Size() = %d, want 100", size)
+	}
+
+	seen := make(map[int]bool)
+	for !s.IsEmpty() {
+		val, err := s.Pop()
+		if err != nil {
+			t.Fatalf("Pop() error = %v, want nil", err)
+		}
+		seen[val] = true
+	}
+	if len(seen) != 100 {
+		t.Errorf("Pop() returned %d distinct items, want 100", len(seen))
+	}
+
+	if _, err := s.Pop(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Pop() on empty stack error = %v, want ErrUnderflow", err)
+	}
+	if _, err := s.Peek(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Peek() on empty stack error = %v, want ErrUnderflow", err)
+	}
+}
+
+func TestShardedPushMany(t *testing.T) {
+	s := NewSharded[int](4, WithCapacity[int](5))
+	_ = s.Push(0)
+
+	if err := s.PushMany(1, 2, 3, 4, 5); !errors.Is(err, ErrOverflow) {
+		t.Errorf("PushMany() exceeding capacity error = %v, want ErrOverflow", err)
+	}
+	if size := s.Size(); size != 1 {
+		t.Errorf("Size after rejected PushMany() = %d, want 1", size)
+	}
+
+	if err := s.PushMany(1, 2, 3, 4); err != nil {
+		t.Errorf("PushMany() error = %v, want nil", err)
+	}
+	if size := s.Size(); size != 5 {
+		t.Errorf("Size after PushMany() = %d, want 5", size)
+	}
+}
+
+func TestShardedCapacityChanges(t *testing.T) {
+	t.Run("SetCapacity", func(t *testing.T) {
+		s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5, 6}))
+
+		dropped, err := s.SetCapacity(4)
+		if err != nil || dropped != 2 {
+			t.Errorf("SetCapacity(4) = %d, %v, want 2, nil", dropped, err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{3, 4, 5, 6}) {
+			t.Errorf("ToSlice() after SetCapacity(4) = %v, want [3 4 5 6]", got)
+		}
+		if err := s.Push(7); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() after SetCapacity(4) error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("ResetWithCapacity", func(t *testing.T) {
+		s := NewSharded[int](3, WithItems([]int{1, 2, 3}))
+
+		if err := s.ResetWithCapacity(2); err != nil {
+			t.Fatalf("ResetWithCapacity(2) error = %v, want nil", err)
+		}
+		if !s.IsEmpty() {
+			t.Errorf("Size after ResetWithCapacity() = %d, want 0", s.Size())
+		}
+		if got := s.Capacity(); got != 2 {
+			t.Errorf("Capacity after ResetWithCapacity(2) = %d, want 2", got)
+		}
+	})
+}
+
+func TestShardedClone(t *testing.T) {
+	s := NewSharded[int](2, WithCapacity[int](4), WithItems([]int{1, 2, 3}))
+	c := s.Clone()
+
+	c.Clear()
+	if size := s.Size(); size != 3 {
+		t.Errorf("original Size after clearing clone = %d, want 3", size)
+	}
+	if got := c.Capacity(); got != 4 {
+		t.Errorf("Clone().Capacity() = %d, want 4", got)
+	}
+}
+
+func TestShardedDeepClone(t *testing.T) {
+	s := NewSharded[*int](2, WithItems([]*int{new(int), new(int), new(int)}))
+	c := s.DeepClone(func(p *int) *int {
+		v := *p
+		return &v
+	})
+
+	for _, p := range c.ToSlice() {
+		*p = 1
+	}
+	for _, p := range s.ToSlice() {
+		if *p != 0 {
+			t.Fatalf("original item after mutating deep clone = %d, want 0", *p)
+		}
+	}
+	if size := c.Size(); size != 3 {
+		t.Errorf("DeepClone().Size() = %d, want 3", size)
+	}
+}
+
+func TestShardedBlocking(t *testing.T) {
+	t.Run("BlockingPop waits for push", func(t *testing.T) {
+		s := NewSharded[int](4)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = s.Push(42)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		val, err := s.BlockingPop(ctx)
+		if err != nil || val != 42 {
+			t.Errorf("BlockingPop() = %d, %v, want 42, nil", val, err)
+		}
+	})
+
+	t.Run("BlockingPush context cancelled", func(t *testing.T) {
+		s := NewSharded[int](2, WithCapacity[int](1), WithItems([]int{1}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := s.BlockingPush(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("BlockingPush() error = %v, want context.DeadlineExceeded", err)
+		}
+		if size := s.Size(); size != 1 {
+			t.Errorf("Size after cancelled BlockingPush() = %d, want 1", size)
+		}
+	})
+}
+
+func TestShardedConcurrency(t *testing.T) {
+	s := NewSharded[int](8)
+	const numGoroutines = 100
+	const numOperations = 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			for j := 0; j < numOperations; j++ {
+				_ = s.Push(start*numOperations + j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if size := s.Size(); size != numGoroutines*numOperations {
+		t.Errorf("Size after concurrent pushes = %d, want %d", size, numGoroutines*numOperations)
+	}
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numOperations; j++ {
+				if _, err := s.Pop(); err != nil {
+					t.Errorf("Pop() error = %v, want nil", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !s.IsEmpty() {
+		t.Errorf("Size after concurrent pops = %d, want 0", s.Size())
+	}
+}
+
+func TestShardedNoSpuriousFailures(t *testing.T) {
+	const numGoroutines = 8
+	s := NewSharded[int](4, WithCapacity[int](numGoroutines))
+
+	var wg sync.WaitGroup
+	for range numGoroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 1000 {
+				// At most numGoroutines items are ever on the stack, and each
+				// goroutine pops only after pushing, so neither call can fail.
+				if err := s.Push(j); err != nil {
+					t.Errorf("Push() error = %v, want nil", err)
+					return
+				}
+				if _, err := s.Pop(); err != nil {
+					t.Errorf("Pop() error = %v, want nil", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	full := NewSharded[int](2, WithCapacity[int](3), WithItems([]int{1, 2, 3}))
+	var overflow *OverflowError
+	if err := full.Push(4); !errors.As(err, &overflow) || overflow.Size != 3 {
+		t.Errorf("Push() on full stack error = %v, want OverflowError with Size 3", err)
+	}
+}
+
+func BenchmarkParallelPushPop(b *testing.B) {
+	s := New[int]()
+	for i := 0; i < 1024; i++ {
+		_ = s.Push(i)
+	}
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = s.Push(1)
+			_, _ = s.Pop()
+		}
+	})
+}
+
+func BenchmarkShardedParallelPushPop(b *testing.B) {
+	s := NewSharded[int](16)
+	for i := 0; i < 1024; i++ {
+		_ = s.Push(i)
+	}
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = s.Push(1)
+			_, _ = s.Pop()
+		}
+	})
+}
+
+func TestShardedForEach(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+
+	var got []int
+	s.ForEach(func(v int) {
+		got = append(got, v)
+	})
+	if !slices.Equal(got, s.ToSlice()) {
+		t.Errorf("ForEach() visited %v, want %v", got, s.ToSlice())
+	}
+
+	got = nil
+	s.ForEachReverse(func(v int) {
+		got = append(got, v)
+	})
+	if !slices.Equal(got, slices.Collect(s.All())) {
+		t.Errorf("ForEachReverse() visited %v, want %v", got, slices.Collect(s.All()))
+	}
+}
+
+func TestShardedFilter(t *testing.T) {
+	s := NewSharded[int](3, WithCapacity[int](10), WithItems([]int{1, 2, 3, 4, 5, 6}))
+
+	even := s.Filter(func(v int) bool { return v%2 == 0 })
+
+	if got := even.ToSlice(); !slices.Equal(got, []int{2, 4, 6}) {
+		t.Errorf("Filter() = %v, want [2 4 6]", got)
+	}
+	if got := even.Capacity(); got != 10 {
+		t.Errorf("Filter().Capacity() = %d, want 10", got)
+	}
+	if size := s.Size(); size != 6 {
+		t.Errorf("source Size after Filter() = %d, want 6", size)
+	}
+}
+
+func TestShardedRetainFunc(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5, 6, 7}))
+	odd := func(v int) bool { return v%2 == 1 }
+	want := slices.DeleteFunc(s.ToSlice(), func(v int) bool { return !odd(v) })
+
+	if removed := s.RetainFunc(odd); removed != 3 {
+		t.Errorf("RetainFunc() = %d, want 3", removed)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, want) {
+		t.Errorf("ToSlice() after RetainFunc() = %v, want %v", got, want)
+	}
+}
+
+func TestShardedDrainAll(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+	want := s.ToSlice()
+
+	if got := s.DrainAll(); !slices.Equal(got, want) {
+		t.Errorf("DrainAll() = %v, want %v", got, want)
+	}
+	if !s.IsEmpty() {
+		t.Errorf("Size() after DrainAll() = %d, want 0", s.Size())
+	}
+}
+
+func TestShardedCompactFunc(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 1, 1, 2, 2, 3, 3}))
+	eq := func(a, b int) bool { return a == b }
+
+	if removed := s.CompactFunc(eq); removed != 4 {
+		t.Errorf("CompactFunc() = %d, want 4", removed)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("ToSlice() after CompactFunc() = %v, want [1 2 3]", got)
+	}
+}
+
+func TestShardedReverse(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+	s.Reverse()
+
+	if got := s.ToSlice(); !slices.Equal(got, []int{5, 4, 3, 2, 1}) {
+		t.Errorf("ToSlice() after Reverse() = %v, want [5 4 3 2 1]", got)
+	}
+}
+
+func TestShardedSwap(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4}))
+
+	if err := s.Swap(); err != nil {
+		t.Fatalf("Swap() error = %v, want nil", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 4, 3}) {
+		t.Errorf("ToSlice() after Swap() = %v, want [1 2 4 3]", got)
+	}
+
+	if err := NewSharded[int](3, WithItems([]int{1})).Swap(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Swap() with one item error = %v, want ErrUnderflow", err)
+	}
+}
+
+func TestShardedSwapAt(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+
+	want := s.ToSlice()
+	n := len(want)
+	want[n-1], want[0] = want[0], want[n-1]
+	if err := s.SwapAt(0, n-1); err != nil {
+		t.Fatalf("SwapAt(0, %d) error = %v, want nil", n-1, err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, want) {
+		t.Errorf("ToSlice() after SwapAt() = %v, want %v", got, want)
+	}
+
+	if err := s.SwapAt(1, n); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("SwapAt(1, %d) error = %v, want ErrIndexOutOfRange", n, err)
+	}
+}
+
+func TestShardedAppendTo(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+
+	got := s.AppendTo([]int{0})
+	if want := append([]int{0}, s.ToSlice()...); !slices.Equal(got, want) {
+		t.Errorf("AppendTo() = %v, want %v", got, want)
+	}
+}
+
+func TestShardedDup(t *testing.T) {
+	s := NewSharded[int](2, WithCapacity[int](4), WithItems([]int{1, 2, 3}))
+
+	if err := s.Dup(); err != nil {
+		t.Fatalf("Dup() error = %v, want nil", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 3, 3}) {
+		t.Errorf("ToSlice() after Dup() = %v, want [1 2 3 3]", got)
+	}
+	if err := s.Dup(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Dup() on full stack error = %v, want ErrOverflow", err)
+	}
+	if err := NewSharded[int](2).Dup(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Dup() on empty stack error = %v, want ErrUnderflow", err)
+	}
+}
+
+func TestShardedSplitAt(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+
+	bottom, top, err := s.SplitAt(2)
+	if err != nil {
+		t.Fatalf("SplitAt(2) error = %v, want nil", err)
+	}
+	if got := bottom.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("bottom after SplitAt(2) = %v, want [1 2]", got)
+	}
+	if got := top.ToSlice(); !slices.Equal(got, []int{3, 4, 5}) {
+		t.Errorf("top after SplitAt(2) = %v, want [3 4 5]", got)
+	}
+
+	if _, _, err := s.SplitAt(3); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("SplitAt(3) on stack of 2 error = %v, want ErrUnderflow", err)
+	}
+	if _, _, err := s.SplitAt(-1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("SplitAt(-1) error = %v, want ErrIndexOutOfRange", err)
+	}
+}
+
+func TestShardedPushBottom(t *testing.T) {
+	s := NewSharded[int](2, WithCapacity[int](4), WithItems([]int{2, 3, 4}))
+
+	if err := s.PushBottom(1); err != nil {
+		t.Fatalf("PushBottom() error = %v, want nil", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("ToSlice() after PushBottom() = %v, want [1 2 3 4]", got)
+	}
+	if err := s.PushBottom(0); !errors.Is(err, ErrOverflow) {
+		t.Errorf("PushBottom() on full stack error = %v, want ErrOverflow", err)
+	}
+}
+
+func TestShardedPopBottom(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4}))
+
+	for want := 1; want <= 4; want++ {
+		if val, err := s.PopBottom(); err != nil || val != want {
+			t.Errorf("PopBottom() = %d, %v, want %d, nil", val, err, want)
+		}
+	}
+	if _, err := s.PopBottom(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("PopBottom() on empty stack error = %v, want ErrUnderflow", err)
+	}
+}
+
+func TestShardedRotate(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4}))
+	before := s.ToSlice()
+
+	if err := s.Rotate(1); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	want := append([]int{before[3]}, before[:3]...)
+	if got := s.ToSlice(); !slices.Equal(got, want) {
+		t.Errorf("ToSlice() after Rotate(1) = %v, want %v", got, want)
+	}
+	if err := s.Rotate(-1); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, before) {
+		t.Errorf("ToSlice() after Rotate(-1) = %v, want %v", got, before)
+	}
+}
+
+func TestShardedPeekN(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+	items := s.ToSlice()
+
+	got, err := s.PeekN(2)
+	if want := []int{items[4], items[3]}; err != nil || !slices.Equal(got, want) {
+		t.Errorf("PeekN(2) = %v, %v, want %v, nil", got, err, want)
+	}
+	if _, err := s.PeekN(-1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("PeekN(-1) error = %v, want ErrIndexOutOfRange", err)
+	}
+}
+
+func TestShardedPeekAt(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+	items := s.ToSlice()
+
+	for depth := range items {
+		want := items[len(items)-1-depth]
+		if val, err := s.PeekAt(depth); err != nil || val != want {
+			t.Errorf("PeekAt(%d) = %d, %v, want %d, nil", depth, val, err, want)
+		}
+	}
+	if _, err := s.PeekAt(len(items)); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("PeekAt(%d) error = %v, want ErrIndexOutOfRange", len(items), err)
+	}
+}
+
+func TestShardedCopyInto(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5}))
+	want := s.ToSlice()
+
+	buf := make([]int, 8)
+	if n := s.CopyInto(buf); n != 5 || !slices.Equal(buf[:n], want) {
+		t.Errorf("CopyInto() = %d, %v, want 5, %v", n, buf[:n], want)
+	}
+
+	short := make([]int, 3)
+	if n := s.CopyInto(short); n != 3 || !slices.Equal(short, want[:3]) {
+		t.Errorf("CopyInto() into short buffer = %d, %v, want 3, %v", n, short, want[:3])
+	}
+}
+
+func TestShardedMinMax(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	s := NewSharded[int](3, WithComparator(less), WithItems([]int{4, 9, 1, 7, 5}))
+
+	if lo, err := s.Min(); err != nil || lo != 1 {
+		t.Errorf("Min() = %d, %v, want 1, nil", lo, err)
+	}
+	if hi, err := s.Max(); err != nil || hi != 9 {
+		t.Errorf("Max() = %d, %v, want 9, nil", hi, err)
+	}
+
+	s.Clear()
+	if _, err := s.Min(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Min() on empty stack error = %v, want ErrUnderflow", err)
+	}
+	if _, err := NewSharded[int](2).Max(); !errors.Is(err, ErrNoComparator) {
+		t.Errorf("Max() without comparator error = %v, want ErrNoComparator", err)
+	}
+}
+
+func TestShardedUpdateTop(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4}))
+	top, _ := s.Peek()
+
+	if err := s.UpdateTop(func(v *int) error {
+		*v *= 10
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateTop() error = %v", err)
+	}
+	if got, _ := s.Peek(); got != top*10 {
+		t.Errorf("Peek() after UpdateTop() = %d, want %d", got, top*10)
+	}
+
+	s.Clear()
+	if err := s.UpdateTop(func(*int) error { return nil }); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("UpdateTop() on empty stack error = %v, want ErrUnderflow", err)
+	}
+}
+
+func TestShardedPopUntil(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5, 6}))
+	view, _ := s.PeekN(6)
+
+	popped, err := s.PopUntil(func(v int) bool { return v != view[3] })
+	if err != nil || !slices.Equal(popped, view[:3]) {
+		t.Fatalf("PopUntil() = %v, %v, want %v, nil", popped, err, view[:3])
+	}
+	if top, _ := s.Peek(); top != view[3] {
+		t.Errorf("Peek() after PopUntil() = %d, want %d", top, view[3])
+	}
+}
+
+func TestShardedFind(t *testing.T) {
+	s := NewSharded[int](3, WithItems([]int{1, 2, 3, 4, 5, 6}))
+	want, _ := s.Peek()
+
+	if val, ok := s.Find(func(int) bool { return true }); !ok || val != want {
+		t.Errorf("Find(always) = %d, %v, want %d, true", val, ok, want)
+	}
+	if _, ok := s.Find(func(v int) bool { return v > 6 }); ok {
+		t.Error("Find(> 6) found an item, want none")
+	}
+}
+
+func TestShardedOverflowPolicy(t *testing.T) {
+	s := NewSharded[int](2,
+		WithCapacity[int](4),
+		WithOverflowPolicy[int](OverflowDropOldest),
+		WithItems([]int{1, 2, 3, 4}),
+	)
+
+	if err := s.Push(5); err != nil {
+		t.Errorf("Push() on full stack error = %v, want nil", err)
+	}
+	if err := s.PushMany(6, 7, 8); err != nil {
+		t.Errorf("PushMany() on full stack error = %v, want nil", err)
+	}
+	if err := s.Dup(); err != nil {
+		t.Errorf("Dup() on full stack error = %v, want nil", err)
+	}
+	if size := s.Size(); size != 4 {
+		t.Errorf("Size after overflowing pushes = %d, want 4", size)
+	}
+}
+
+// stackKinds holds the constructors of plain stacks and of sharded stacks with
+// two shards, for tests that must pass for both.
+var stackKinds = map[string]func(...Option[int]) Stack[int]{
+	"plain":   New[int],
+	"sharded": func(opts ...Option[int]) Stack[int] { return NewSharded(2, opts...) },
+}
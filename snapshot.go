@@ -0,0 +1,77 @@
+package stack
+
+import (
+	"fmt"
+)
+
+// Snapshot is an opaque, immutable copy of the items of a stack at a point in
+// time, created by Snapshot and applied with Restore. A snapshot is independent
+// of the stack it was taken from and may be restored any number of times, to
+// that stack or to another. The zero Snapshot holds no items.
+type Snapshot[T any] struct {
+	items []T
+}
+
+// Len returns the number of items captured by the snapshot.
+func (snap Snapshot[T]) Len() int {
+	return len(snap.items)
+}
+
+func (s *stack[T]) Snapshot() Snapshot[T] {
+	s.rlock()
+	defer s.runlock()
+
+	items := make([]T, len(s.items))
+	copy(items, s.items)
+
+	return Snapshot[T]{items: items}
+}
+
+func (s *stack[T]) Restore(snap Snapshot[T]) error {
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if s.capacity >= 0 && len(snap.items) > s.capacity {
+		return fmt.Errorf("%w: %d item(s) over capacity", ErrOverflow, len(snap.items)-s.capacity)
+	}
+	if size := s.sizeSum(snap.items); s.sizeOf != nil && size > s.maxBytes {
+		return fmt.Errorf("%w: %d byte(s) over limit", ErrOverflow, size-s.maxBytes)
+	}
+
+	clear(s.items)
+	s.items = append(s.items[:0], snap.items...)
+	s.reindex()
+	s.trackDepth()
+	s.broadcast()
+
+	return nil
+}
+
+func (s *sharded[T]) Snapshot() Snapshot[T] {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	return Snapshot[T]{items: s.gather()}
+}
+
+func (s *sharded[T]) Restore(snap Snapshot[T]) error {
+	s.lockAll()
+	if err := s.sealed(); err != nil {
+		s.unlockAll()
+		return err
+	}
+	if s.capacity >= 0 && len(snap.items) > s.capacity {
+		s.unlockAll()
+		return fmt.Errorf("%w: %d item(s) over capacity", ErrOverflow, len(snap.items)-s.capacity)
+	}
+
+	s.scatter(snap.items)
+	s.unlockAll()
+
+	s.notify()
+
+	return nil
+}
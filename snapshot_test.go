@@ -0,0 +1,56 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	for name, newStack := range stackKinds {
+		t.Run(name, func(t *testing.T) {
+			s := newStack(WithCapacity[int](4), WithItems([]int{1, 2}))
+
+			snap := s.Snapshot()
+			if snap.Len() != 2 {
+				t.Errorf("Snapshot().Len() = %d, want 2", snap.Len())
+			}
+
+			_ = s.PushMany(3, 4)
+			full := s.ToSlice()
+			later := s.Snapshot()
+			s.Clear()
+
+			if err := s.Restore(snap); err != nil {
+				t.Fatalf("Restore() error = %v, want nil", err)
+			}
+			if got := s.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+				t.Errorf("ToSlice() after Restore() = %v, want [1 2]", got)
+			}
+
+			// Snapshots are independent of each other and of the stack.
+			_, _ = s.Pop()
+			if err := s.Restore(later); err != nil {
+				t.Fatalf("Restore() error = %v, want nil", err)
+			}
+			if got := s.ToSlice(); !slices.Equal(got, full) {
+				t.Errorf("ToSlice() after second Restore() = %v, want %v", got, full)
+			}
+			if err := s.Restore(later); err != nil || s.Size() != 4 {
+				t.Errorf("restoring the same snapshot twice = %v with size %d", err, s.Size())
+			}
+
+			_, _ = s.SetCapacity(3)
+			if err := s.Restore(later); !errors.Is(err, ErrOverflow) {
+				t.Errorf("Restore() of oversized snapshot error = %v, want ErrOverflow", err)
+			}
+			if s.Size() != 3 {
+				t.Errorf("Size() after failed Restore() = %d, want 3", s.Size())
+			}
+
+			if err := s.Restore(Snapshot[int]{}); err != nil || !s.IsEmpty() {
+				t.Errorf("Restore() of zero Snapshot = %v, size %d, want empty", err, s.Size())
+			}
+		})
+	}
+}
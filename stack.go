@@ -1,7 +1,8 @@
 // Package stack provides a thread-safe, generic stack implementation with configurable capacity limits.
 //
 // The stack follows LIFO (Last-In-First-Out) semantics and supports any type through Go generics.
-// All operations are safe for concurrent use across multiple goroutines.
+// All operations are safe for concurrent use across multiple goroutines, unless thread
+// safety is explicitly disabled with WithThreadSafety.
 //
 // Example usage:
 //
@@ -12,26 +13,435 @@
 package stack
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Stack defines the interface for a generic stack data structure.
-// All operations are thread-safe and support any type T.
+// All operations are thread-safe and support any type T, unless the stack
+// was created with WithThreadSafety(false).
 type Stack[T any] interface {
 	// Push adds an item to the top of the stack.
-	// Returns ErrOverflow if the stack is at capacity.
+	// Returns an *OverflowError wrapping ErrOverflow if the stack is at capacity,
+	// unless the overflow policy evicts the oldest item to make room.
 	Push(val T) error
 
+	// PushMany adds all items to the stack in order, so the last item ends up on top.
+	// The operation is all-or-nothing: if the items do not all fit, none are pushed
+	// and an error wrapping ErrOverflow is returned. Under OverflowDropOldest the
+	// oldest items are evicted instead, as if the items were pushed one at a time.
+	PushMany(vals ...T) error
+
 	// Pop removes and returns the top item from the stack.
-	// Returns ErrUnderflow if the stack is empty.
+	// Returns an *UnderflowError wrapping ErrUnderflow if the stack is empty.
 	Pop() (T, error)
 
 	// Size returns the current number of items in the stack.
 	Size() int
 
 	// Peek returns the top item without removing it from the stack.
-	// Returns ErrUnderflow if the stack is empty.
+	// Returns an *UnderflowError wrapping ErrUnderflow if the stack is empty.
 	Peek() (T, error)
+
+	// Clear removes all items from the stack.
+	// The underlying storage is retained for reuse by subsequent pushes.
+	Clear()
+
+	// Capacity returns the maximum number of items the stack can hold,
+	// or UnlimitedCapacity if the stack has no size limit.
+	Capacity() int
+
+	// IsEmpty reports whether the stack contains no items.
+	IsEmpty() bool
+
+	// IsFull reports whether the stack has reached its capacity.
+	// Always returns false for stacks with UnlimitedCapacity.
+	IsFull() bool
+
+	// ToSlice returns a copy of the items ordered from bottom to top.
+	// The returned slice is never nil and may be freely modified by the caller.
+	ToSlice() []T
+
+	// Clone returns an independent copy of the stack with the same capacity and items.
+	// Changes to the clone do not affect the original and vice versa.
+	Clone() Stack[T]
+
+	// TryPush adds an item to the top of the stack if there is room.
+	// Returns false without modifying the stack if it is at capacity.
+	TryPush(val T) bool
+
+	// TryPop removes and returns the top item from the stack.
+	// Returns the zero value and false if the stack is empty.
+	TryPop() (T, bool)
+
+	// BlockingPop removes and returns the top item from the stack, waiting for
+	// an item to be pushed if the stack is empty.
+	// Returns ctx.Err() if the context is done before an item becomes available.
+	BlockingPop(ctx context.Context) (T, error)
+
+	// BlockingPush adds an item to the top of the stack, waiting for room to
+	// become available if the stack is at capacity.
+	// Returns ctx.Err() without pushing if the context is done first.
+	BlockingPush(ctx context.Context, val T) error
+
+	// All returns an iterator over the items from top to bottom (LIFO order).
+	// The iterator ranges over a snapshot taken when iteration starts, so the
+	// stack may be modified concurrently without affecting the iteration.
+	All() iter.Seq[T]
+
+	// Drain returns an iterator that pops and yields items from top to bottom
+	// until the stack is empty. Each item is popped just before it is yielded,
+	// so breaking out of the loop early leaves the remaining items on the stack.
+	Drain() iter.Seq[T]
+
+	// PeekN returns the top n items without removing them, ordered from top to bottom.
+	// Returns an *UnderflowError wrapping ErrUnderflow, and no items, if the
+	// stack holds fewer than n items, or ErrIndexOutOfRange if n is negative.
+	PeekN(n int) ([]T, error)
+
+	// ResetWithCapacity removes all items and changes the capacity of the stack.
+	// Returns ErrInvalidCapacity, leaving the stack unchanged, if capacity is
+	// less than UnlimitedCapacity.
+	ResetWithCapacity(capacity int) error
+
+	// SetCapacity changes the capacity of the stack without removing items that fit.
+	// If the stack holds more items than the new capacity, the excess items are
+	// dropped from the bottom so the newest items are kept. Returns the number of
+	// dropped items, or ErrInvalidCapacity if capacity is less than UnlimitedCapacity.
+	SetCapacity(capacity int) (int, error)
+
+	// Grow ensures the stack has storage for at least n more items without
+	// reallocating. For bounded stacks the storage never grows beyond the capacity.
+	// Grow is a performance hint only and does not change the size of the stack.
+	// Panics if n is negative.
+	Grow(n int)
+
+	// ShrinkToFit reallocates the storage of the stack so it holds no more room
+	// than needed for the current items, releasing memory after a large burst.
+	// Shrinking an empty stack releases all of its storage. Stacks created with
+	// WithFixedArray keep their storage, since it is allocated for their whole
+	// capacity by design.
+	ShrinkToFit()
+
+	// ForEach calls fn for each item from bottom to top.
+	// The read lock is held for the duration of the call, so fn must not call
+	// methods that modify the stack or it will deadlock.
+	ForEach(fn func(T))
+
+	// ForEachReverse calls fn for each item from top to bottom.
+	// The same locking rules as ForEach apply.
+	ForEachReverse(fn func(T))
+
+	// Filter returns a new stack with the same configuration containing, in their
+	// original order, only the items for which pred returns true. The source stack
+	// is not modified, and pred is called on a snapshot without holding the lock.
+	Filter(pred func(T) bool) Stack[T]
+
+	// Reverse reverses the order of the items in place, so the bottom item
+	// becomes the top. Size and capacity are unchanged.
+	Reverse()
+
+	// Swap exchanges the top two items.
+	// Returns ErrUnderflow if the stack holds fewer than two items.
+	Swap() error
+
+	// Dup pushes a copy of the top item as a single atomic operation.
+	// Returns ErrUnderflow if the stack is empty, or ErrOverflow if it is at capacity.
+	Dup() error
+
+	// Stats returns cumulative counters describing how the stack has been used.
+	// The counters are maintained atomically and reading them does not take the lock.
+	// For a stack created with NewSharded, MaxSize is the sum of the shards'
+	// individual maximums, which is an upper bound on the true maximum.
+	Stats() Stats
+
+	// MaxDepth returns the largest number of items the stack has held since it
+	// was created or ResetMaxDepth was last called. It never decreases as items
+	// are popped or cleared. For a stack created with NewSharded it is the sum of
+	// the shards' individual maximums.
+	MaxDepth() int
+
+	// ResetMaxDepth restarts MaxDepth tracking from the current size.
+	// It does not affect the MaxSize reported by Stats.
+	ResetMaxDepth()
+
+	// WriteTo implements io.WriterTo, writing the items from bottom to top as
+	// length-prefixed records that ReadFromFunc reads back; see WriteToFunc.
+	// Items are converted to bytes with the encoder registered by WithEncoder;
+	// strings and byte slices are written as-is when no encoder is registered.
+	// Returns ErrNoEncoder if the items cannot be encoded, or the first error
+	// returned by w.
+	WriteTo(w io.Writer) (int64, error)
+
+	// SplitAt atomically moves the items above the bottom n into a new stack.
+	// The receiver keeps its bottom n items and is returned as bottom; top is a
+	// new stack with the same configuration holding the moved items in their
+	// original order. Returns ErrIndexOutOfRange if n is negative, or
+	// ErrUnderflow if n exceeds the current size.
+	SplitAt(n int) (bottom, top Stack[T], err error)
+
+	// AsReadOnly returns a view of the stack that exposes only the methods that
+	// do not modify it. The view shares the items and lock of the stack, so it
+	// always reflects the current contents.
+	AsReadOnly() ReadOnly[T]
+
+	// Snapshot returns a copy of the current items that can later be passed to
+	// Restore. Snapshots are independent of the stack and of each other.
+	Snapshot() Snapshot[T]
+
+	// Restore atomically replaces the items of the stack with those captured by
+	// snap. The capacity and configuration of the stack are unchanged. Returns
+	// ErrOverflow, leaving the stack unchanged, if snap holds more items than the
+	// current capacity allows.
+	Restore(snap Snapshot[T]) error
+
+	// PopOr removes and returns the top item, or returns def without modifying
+	// the stack if it is empty. An empty stack is not counted as an underflow.
+	PopOr(def T) T
+
+	// PeekOr returns the top item without removing it, or def if the stack is empty.
+	PeekOr(def T) T
+
+	// Transaction runs fn with a staged view of the stack and applies the changes
+	// fn makes through it atomically. If fn returns nil, every staged push and pop
+	// is committed at once; if fn returns an error, the changes are discarded and
+	// the error is returned.
+	//
+	// The write lock is held while fn runs, so no other goroutine observes the
+	// stack mid-transaction. For the same reason fn must only use tx, and must
+	// not call methods on the stack itself or it will deadlock.
+	Transaction(fn func(tx TxStack[T]) error) error
+
+	// PopWithTimeout removes and returns the top item from the stack, waiting up
+	// to d for an item to be pushed if the stack is empty.
+	// Returns ErrTimeout if no item becomes available in time.
+	PopWithTimeout(d time.Duration) (T, error)
+
+	// Notify returns a channel that receives a signal whenever a push makes the
+	// empty stack non-empty, so consumers can select on it instead of polling.
+	// Every call returns the same channel. Items already on the stack when the
+	// channel is first requested are not signalled.
+	//
+	// Signals are sent without blocking into a buffer of one, so several
+	// transitions may coalesce into a single signal, and another goroutine may
+	// take the items before the receiver wakes. Consumers should drain the stack
+	// with TryPop after each signal rather than assume an item is waiting.
+	// Sharded stacks may also signal while other shards still hold items.
+	Notify() <-chan struct{}
+
+	// Close permanently shuts down the stack. Goroutines blocked in BlockingPop,
+	// BlockingPush or PopWithTimeout are woken and return ErrClosed. Afterwards,
+	// every method that modifies the stack fails as it would after Freeze but
+	// with ErrClosed: methods with an error to report return ErrClosed, TryPush
+	// and TryPop report false, PopOr returns its default, Drain yields nothing,
+	// and Clear, Reverse, RetainFunc and CompactFunc leave the stack unchanged.
+	// The one exception is DrainAll, which still removes and returns the
+	// remaining items so that they can be collected. Methods that only read
+	// the stack keep working. Close also stops the goroutine started by
+	// WithMetricsReporter.
+	//
+	// Returns ErrClosed if the stack was already closed.
+	Close() error
+
+	// RemainingCapacity returns how many more items can be pushed before the
+	// stack is full, which is zero for a full stack. For stacks with
+	// UnlimitedCapacity it returns UnlimitedCapacity (-1), so callers must check
+	// for that value before comparing it with a batch size. The byte limit set by
+	// WithMaxBytes is not taken into account.
+	//
+	// The result may be out of date as soon as it is returned if other goroutines
+	// modify the stack; use PushMany for an atomic all-or-nothing push.
+	RemainingCapacity() int
+
+	// PushBottom inserts an item at the bottom of the stack, below every other item.
+	// Unlike Push it is O(n), as every item is shifted up to make room.
+	// Returns an *OverflowError wrapping ErrOverflow if the stack is at capacity.
+	// The overflow policy does not apply, as the new item would itself be the
+	// oldest and so the first to be evicted.
+	PushBottom(val T) error
+
+	// PopBottom removes and returns the bottom (oldest) item of the stack.
+	// Unlike Pop it is O(n), as every remaining item is shifted down.
+	// Returns an *UnderflowError wrapping ErrUnderflow if the stack is empty.
+	PopBottom() (T, error)
+
+	// Rotate cycles the items of the stack in a single O(n) pass. A positive n
+	// moves the top item to the bottom n times; a negative n moves the bottom
+	// item to the top -n times. Rotating an empty stack, or by a multiple of its
+	// size, leaves it unchanged. Returns ErrClosed if the stack has been closed.
+	Rotate(n int) error
+
+	// PeekAt returns the item at the given depth without removing it, where
+	// depth 0 is the top item. Returns ErrIndexOutOfRange if depth is negative
+	// or not less than the size of the stack.
+	PeekAt(depth int) (T, error)
+
+	// CopyInto copies the items, ordered from bottom to top, into dst and
+	// returns the number copied. It allocates nothing, so dst can be reused
+	// across calls. If dst is shorter than the stack, only the bottom len(dst)
+	// items are copied and the rest are silently left out.
+	CopyInto(dst []T) int
+
+	// Min returns the smallest item on the stack without removing it, in O(1)
+	// time (O(shards) for a sharded stack). Returns ErrNoComparator if the
+	// stack has no ordering, or ErrUnderflow if the stack is empty.
+	Min() (T, error)
+
+	// Max returns the largest item on the stack without removing it, in O(1)
+	// time (O(shards) for a sharded stack). Returns ErrNoComparator if the
+	// stack has no ordering, or ErrUnderflow if the stack is empty.
+	Max() (T, error)
+
+	// DrainTo pops up to n items, or every item if n is negative, and pushes
+	// each onto dst as it goes, so the items end up on dst in reverse order.
+	// It returns the number of items moved. Both stacks are locked together
+	// in a consistent order, so concurrent transfers in opposite directions
+	// cannot deadlock.
+	//
+	// If dst overflows, the item that did not fit is pushed back onto this
+	// stack and DrainTo returns the count so far with dst's *OverflowError.
+	// Returns ErrClosed or ErrFrozen if either stack has been closed or
+	// frozen. Draining a stack into itself moves nothing.
+	DrainTo(dst Stack[T], n int) (int, error)
+
+	// DeepClone is like Clone, but passes every item through clone on its way
+	// into the new stack, so that items holding pointers, slices or maps need
+	// not share them with the original. How deep the copy goes is up to clone.
+	// clone is called under the read lock and must not modify the stack.
+	DeepClone(clone func(T) T) Stack[T]
+
+	// UpdateTop calls fn with a pointer to a copy of the top item, holding the
+	// write lock throughout, and stores the copy back as the new top item only
+	// if fn returns nil. If fn returns an error, its changes to the copy are
+	// discarded, the top item is left unchanged and the error is returned. The
+	// pointer must not be retained after fn returns. The update does not count
+	// as a push or pop for observers and Stats.
+	//
+	// Returns ErrUnderflow if the stack is empty, ErrOverflow if the updated
+	// item exceeds the limit set by WithMaxBytes, or ErrClosed if the stack has
+	// been closed. fn must not call methods on the stack.
+	UpdateTop(fn func(top *T) error) error
+
+	// RetainFunc removes, in place, every item for which pred returns false,
+	// keeping the order of the rest, and returns the number of items removed.
+	// Unlike Filter it allocates no new stack, which suits long-lived stacks
+	// that are pruned periodically. pred is called under the write lock and
+	// must not call methods on the stack. Removed items are not reported to
+	// observers or counted as pops.
+	RetainFunc(pred func(T) bool) int
+
+	// PopUntil pops items from the top for as long as pred returns true and
+	// returns them in the order they were popped. The first item for which pred
+	// returns false is left on the stack. All of this happens atomically under
+	// the write lock; pred must not call methods on the stack. A sharded stack
+	// pops in the order of its combined view, as PeekN lists it.
+	//
+	// Returns no items if the stack is empty or pred rejects the top item, or
+	// ErrClosed if the stack has been closed.
+	PopUntil(pred func(T) bool) ([]T, error)
+
+	// PushSeq pushes every value yielded by seq, in order, as a single
+	// PushMany. seq is run to completion before the stack is locked, so it may
+	// be slow or call methods on the stack. The push is all-or-nothing: if the
+	// values do not all fit, none are pushed and an error wrapping ErrOverflow
+	// is returned, subject to the overflow policy as for PushMany.
+	PushSeq(seq iter.Seq[T]) error
+
+	// DrainAll atomically removes every item and returns them ordered from
+	// bottom to top. The stack keeps no reference to the returned slice and
+	// continues with fresh storage, so producers can keep pushing while the
+	// batch is processed. Removed items are not reported to observers or
+	// counted as pops. DrainAll also works on a closed stack, which makes it
+	// a way to collect whatever was left behind.
+	DrainAll() []T
+
+	// Name returns the name set with WithName, or "" if the stack is unnamed.
+	Name() string
+
+	// CompactFunc removes, in place, every item that eq reports equal to the
+	// item below it, keeping the bottom item of each run, like
+	// slices.CompactFunc. It returns the number of items removed. eq is called
+	// under the write lock and must not call methods on the stack. Removed
+	// items are not reported to observers or counted as pops.
+	CompactFunc(eq func(a, b T) bool) int
+
+	// Find returns the topmost item for which pred returns true, scanning down
+	// from the top, and reports whether one was found. The stack is not
+	// modified. pred is called under the read lock and must not modify the
+	// stack.
+	Find(pred func(T) bool) (T, bool)
+
+	// PopWhile pops the top item and passes it to fn, repeating for as long as
+	// fn returns true and the stack is not empty, and returns the number of
+	// items popped. The item for which fn returns false has already been
+	// popped and is included in the count.
+	//
+	// Each pop takes the lock on its own and fn runs without holding it, so fn
+	// may perform I/O or use the stack. Other goroutines may push or pop
+	// between iterations, so the items passed to fn need not have been
+	// adjacent, and items pushed meanwhile are popped too. Use PopUntil to pop
+	// a run of items atomically.
+	PopWhile(fn func(T) bool) int
+
+	// Utilization returns the size of the stack as a fraction of its capacity,
+	// from 0 for an empty stack to 1 for a full one, reading both under the
+	// same lock. A stack with zero capacity is always full and reports 1. For
+	// stacks with UnlimitedCapacity, where the fraction is undefined, it
+	// returns -1. The byte limit set by WithMaxBytes is not taken into account.
+	Utilization() float64
+
+	// Freeze makes the stack read-only. Afterwards, the methods that return
+	// ErrClosed once the stack is closed return ErrFrozen instead, TryPush and
+	// TryPop report false, and the other methods that modify the stack either
+	// return ErrFrozen or, when they have no error to report, leave it
+	// unchanged: Clear, Reverse and DrainAll do nothing, and RetainFunc and
+	// CompactFunc remove nothing. Goroutines blocked in BlockingPop or
+	// BlockingPush are woken and return ErrFrozen. Methods that only read the
+	// stack keep working. Freezing a frozen stack has no effect, and a frozen
+	// stack that is also closed reports ErrClosed.
+	Freeze()
+
+	// SwapAt exchanges the items at depths i and j, where depth 0 is the top
+	// item, generalizing Swap to any two positions. Returns ErrIndexOutOfRange
+	// if either depth is negative or not less than the size of the stack.
+	SwapAt(i, j int) error
+
+	// AppendTo appends the items, ordered from bottom to top, to dst and
+	// returns the extended slice, following the append idiom. Unlike ToSlice,
+	// it allocates only if dst lacks the capacity to hold the items.
+	AppendTo(dst []T) []T
+
+	// PushWithBackoff calls Push until it succeeds, sleeping for
+	// backoff(attempt) after each attempt that fails with ErrOverflow, where
+	// attempt counts the failures so far, starting at 1. Unlike BlockingPush,
+	// it polls rather than waiting for a pop, so it also retries pushes
+	// rejected by the byte limit set by WithMaxBytes. Each failed attempt is
+	// counted as an overflow. Other errors, such as ErrClosed, are returned at
+	// once, and ctx.Err() is returned if ctx is done while sleeping.
+	PushWithBackoff(ctx context.Context, val T, backoff func(attempt int) time.Duration) error
+
+	// WaitUntilEmpty blocks until the stack is empty, such as after consumers
+	// have popped every item, and returns nil at once if it already is. It
+	// wakes on every change, like BlockingPop. Returns ErrClosed or ErrFrozen
+	// if the stack is closed or frozen while it still holds items, or
+	// ctx.Err() if ctx is done first.
+	WaitUntilEmpty(ctx context.Context) error
+
+	// WaitForSize blocks until the stack holds at least n items, and returns
+	// nil at once if it already does. With a context that times out, it
+	// supports flushing a batch once n items accumulate or a deadline passes.
+	// Returns ErrClosed or ErrFrozen if the stack is closed or frozen while it
+	// holds fewer than n items, or ctx.Err() if ctx is done first. If n
+	// exceeds the capacity of the stack, it waits until the capacity is raised
+	// or ctx is done.
+	WaitForSize(ctx context.Context, n int) error
 }
 
 // New creates a new stack with the specified options.
@@ -41,17 +451,228 @@ type Stack[T any] interface {
 //
 //	s := stack.New[int]()                           // Unlimited capacity
 //	s := stack.New[int](stack.WithCapacity[int](10)) // Capacity of 10
+//
+// Panics if the options are invalid; use NewChecked to get an error instead.
 func New[T any](opts ...Option[T]) Stack[T] {
-	return newStack(opts...)
+	s := newStack(opts...)
+	s.startReporting()
+
+	return s
+}
+
+// NewChecked is like New, but returns an error instead of panicking if the
+// options are invalid, which makes it suitable for capacities and limits taken
+// from untrusted configuration. The error wraps ErrInvalidCapacity for a bad
+// capacity, ErrInvalidOption for other bad options, or ErrOverflow if the
+// items given with WithItems do not fit.
+//
+// Example:
+//
+//	s, err := stack.NewChecked[int](stack.WithCapacity[int](cfg.Capacity))
+//	if errors.Is(err, stack.ErrInvalidCapacity) {
+//		return fmt.Errorf("bad stack capacity in config: %w", err)
+//	}
+func NewChecked[T any](opts ...Option[T]) (Stack[T], error) {
+	s, err := buildStack(opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.startReporting()
+
+	return s, nil
+}
+
+// NewFromSlice creates a new stack holding a copy of items, with items[0] at the
+// bottom and items[len(items)-1] on top. Options are applied as for New, except
+// that WithItems is ignored.
+//
+// Unlike WithItems, which panics, NewFromSlice returns an error wrapping
+// ErrOverflow if items do not fit within the configured capacity, or wrapping
+// ErrDuplicate if they contain duplicates and WithUniqueness is given. Invalid
+// options are reported as by NewChecked rather than causing a panic.
+//
+// Example:
+//
+//	s, err := stack.NewFromSlice([]int{1, 2, 3}, stack.WithCapacity[int](10))
+//	val, _ := s.Pop() // returns 3
+func NewFromSlice[T any](items []T, opts ...Option[T]) (Stack[T], error) {
+	s, err := buildStack(append(slices.Clip(opts), WithItems[T](nil))...)
+	if err != nil {
+		return nil, err
+	}
+	if !s.fits(items...) {
+		return nil, s.overflowError(items)
+	}
+	if s.repeats(items) {
+		return nil, fmt.Errorf("%w: cannot seed duplicate items", ErrDuplicate)
+	}
+
+	s.items = append(s.items, items...)
+	s.reindex()
+	s.trackDepth()
+	s.startReporting()
+
+	return s, nil
 }
 
 type stack[T any] struct {
 	mu       sync.RWMutex
+	unsynced bool
+	name     string
 	capacity int
+	policy   OverflowPolicy
 	items    []T
+
+	// less orders items for Min and Max. When set, mins and maxs hold the
+	// running minimum and maximum at each depth, parallel to items.
+	less       func(a, b T) bool
+	mins, maxs []T
+
+	// onEvict is called, after the lock is released, for each item evicted by
+	// the overflow policy. evicted buffers those items until release runs.
+	onEvict func(T)
+	evicted []T
+
+	// observer is notified, after the lock is released, of each successful
+	// push and pop. pushed and popped buffer the items until release runs.
+	observer       Observer[T]
+	pushed, popped []T
+
+	stats counters
+
+	// maxBytes limits the total of sizeOf over all items when sizeOf is set.
+	// bytes is the running total, maintained by push, pop and reindex.
+	// See WithMaxBytes.
+	maxBytes int
+	sizeOf   func(T) int
+	bytes    int
+
+	// unique, if set, tracks the items on the stack, maintained by push, pop
+	// and reindex, so that pushes of items already present are refused with
+	// ErrDuplicate or, unless rejectDuplicates is set, skipped silently.
+	// See WithUniqueness.
+	unique           itemSet[T]
+	rejectDuplicates bool
+
+	// initialCapacity is the number of items to allocate storage for when the
+	// stack is created. See WithInitialCapacity.
+	initialCapacity int
+
+	// fixed keeps storage for exactly capacity items allocated at all times,
+	// so that pushes never allocate. See WithFixedArray.
+	fixed bool
+
+	// growth, if set, chooses the new storage size when a push finds the
+	// storage full. See WithGrowthPolicy.
+	growth func(current int) int
+
+	// err records the first invalid option applied during construction. New
+	// panics with it and NewChecked returns it.
+	err error
+
+	// encode converts an item to bytes for WriteTo. See WithEncoder.
+	encode func(T) []byte
+
+	// encodeBinary and decodeBinary convert items for MarshalBinary and
+	// UnmarshalBinary. See WithBinaryCodec.
+	encodeBinary func(T) ([]byte, error)
+	decodeBinary func([]byte) (T, error)
+
+	// maxDepth is the largest number of items held since creation or the
+	// last ResetMaxDepth. Unlike stats, it is guarded by the lock.
+	maxDepth int
+
+	// changed is closed and cleared whenever the stack is modified,
+	// waking goroutines blocked waiting for a change. See waitChange.
+	changed chan struct{}
+
+	// ready, once created by Notify, is signalled by broadcast when the stack
+	// goes from empty to non-empty. hadItems records whether the stack held
+	// items at the previous broadcast.
+	ready    chan struct{}
+	hadItems bool
+
+	// signal receives a non-blocking send after every successful push.
+	// See WithSignalChannel.
+	signal chan<- struct{}
+
+	// onEmpty is called after a change that leaves the stack empty when it
+	// held items as the write lock was acquired, at which point lock records
+	// the size in lockedSize. See WithOnEmpty.
+	onEmpty    func()
+	lockedSize int
+
+	// closed is set by Close. It is only set while holding the write lock,
+	// but may be read without it.
+	closed atomic.Bool
+
+	// frozen is set by Freeze. Like closed, it is only set while holding the
+	// write lock, but may be read without it.
+	frozen atomic.Bool
+
+	// report is called with Stats every reportEvery by the goroutine started
+	// by startReporting, until Close closes done. See WithMetricsReporter.
+	reportEvery time.Duration
+	report      func(Stats)
+	done        chan struct{}
+}
+
+// sealed returns ErrClosed if the stack has been closed, ErrFrozen if it has
+// been frozen, and nil if items may still be pushed and popped.
+func (s *stack[T]) sealed() error {
+	switch {
+	case s.closed.Load():
+		return ErrClosed
+	case s.frozen.Load():
+		return ErrFrozen
+	default:
+		return nil
+	}
+}
+
+// lock acquires the write lock unless the stack was created without thread safety.
+func (s *stack[T]) lock() {
+	if !s.unsynced {
+		s.mu.Lock()
+	}
+	if s.onEmpty != nil {
+		s.lockedSize = len(s.items)
+	}
 }
 
+// unlock releases the write lock acquired by lock.
+func (s *stack[T]) unlock() {
+	if !s.unsynced {
+		s.mu.Unlock()
+	}
+}
+
+// rlock acquires the read lock unless the stack was created without thread safety.
+func (s *stack[T]) rlock() {
+	if !s.unsynced {
+		s.mu.RLock()
+	}
+}
+
+// runlock releases the read lock acquired by rlock.
+func (s *stack[T]) runlock() {
+	if !s.unsynced {
+		s.mu.RUnlock()
+	}
+}
+
+// newStack creates a stack from opts, panicking if they are invalid.
 func newStack[T any](opts ...Option[T]) *stack[T] {
+	s, err := buildStack(opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// buildStack creates a stack from opts, or returns the first reason they are invalid.
+func buildStack[T any](opts ...Option[T]) (*stack[T], error) {
 	s := &stack[T]{
 		capacity: UnlimitedCapacity,
 	}
@@ -59,60 +680,1074 @@ func newStack[T any](opts ...Option[T]) *stack[T] {
 		opt(s)
 	}
 
-	s.items = make([]T, 0)
+	if s.items == nil {
+		s.items = make([]T, 0)
+	}
+	if s.capacity >= 0 && len(s.items) > s.capacity {
+		s.reject(fmt.Errorf("%w: cannot seed %d item(s) over capacity", ErrOverflow, len(s.items)-s.capacity))
+	}
+	if size := s.sizeSum(s.items); s.sizeOf != nil && size > s.maxBytes {
+		s.reject(fmt.Errorf("%w: cannot seed %d byte(s) over limit", ErrOverflow, size-s.maxBytes))
+	}
+	if s.repeats(s.items) {
+		s.reject(fmt.Errorf("%w: cannot seed duplicate items", ErrDuplicate))
+	}
+	if s.fixed && s.capacity < 0 {
+		s.reject(fmt.Errorf("%w: cannot back an unlimited stack with a fixed array", ErrInvalidCapacity))
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
 
-	return s
+	s.fix()
+	s.grow(s.initialCapacity)
+	s.reindex()
+	s.trackDepth()
+
+	return s, nil
+}
+
+// reject records err as the reason the options are invalid, unless an earlier
+// option has already been rejected.
+func (s *stack[T]) reject(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// fix allocates storage for exactly capacity items if the stack is backed by a
+// fixed array. It must be called whenever items or capacity are replaced.
+// Callers must hold the write lock.
+func (s *stack[T]) fix() {
+	if !s.fixed || s.capacity < 0 || cap(s.items) == s.capacity {
+		return
+	}
+
+	items := make([]T, len(s.items), max(s.capacity, len(s.items)))
+	copy(items, s.items)
+	s.items = items
 }
 
 func (s *stack[T]) Push(val T) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock()
+	defer s.release()
 
-	if s.capacity >= 0 && len(s.items)+1 > s.capacity {
-		return ErrOverflow
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if dup, err := s.duplicate(val); dup {
+		return err
+	}
+	if !s.reserve(val) {
+		s.stats.overflows.Add(1)
+		return &OverflowError{Name: s.name, Capacity: s.capacity, Size: len(s.items)}
 	}
 
-	s.items = append(s.items, val)
+	s.push(val)
+	s.didPush(val)
+	s.broadcast()
+
+	return nil
+}
+
+func (s *stack[T]) PushMany(vals ...T) error {
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	vals, err := s.distinct(vals)
+	if err != nil {
+		return err
+	}
+	if !s.fits(vals...) {
+		if s.policy != OverflowDropOldest || s.capacity == 0 || !s.eachWithin(vals) {
+			s.stats.overflows.Add(1)
+			return s.overflowError(vals)
+		}
+
+		// Items that would be evicted by later items in the same batch
+		// are skipped rather than pushed and then evicted.
+		skip := 0
+		for !s.within(len(vals)-skip, s.sizeSum(vals[skip:])) {
+			skip++
+		}
+		s.reserve(vals[skip:]...)
+		if s.onEvict != nil {
+			s.evicted = append(s.evicted, vals[:skip]...)
+		}
+		vals = vals[skip:]
+	}
+
+	for _, val := range vals {
+		s.push(val)
+		s.didPush(val)
+	}
+	s.broadcast()
 
 	return nil
 }
 
 func (s *stack[T]) Pop() (T, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock()
+	defer s.release()
 
-	sz := len(s.items)
-	if sz == 0 {
+	if err := s.sealed(); err != nil {
 		var zero T
-		return zero, ErrUnderflow
+		return zero, err
+	}
+	if len(s.items) == 0 {
+		s.stats.underflows.Add(1)
+		var zero T
+		return zero, &UnderflowError{Name: s.name, Capacity: s.capacity}
 	}
 
-	idx := sz - 1
-
-	result := s.items[idx]
-	s.items = s.items[:idx]
+	result := s.pop()
+	s.didPop(result)
+	s.broadcast()
 
 	return result, nil
 }
 
 func (s *stack[T]) Size() int {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.rlock()
+	defer s.runlock()
 
 	return len(s.items)
 }
 
 func (s *stack[T]) Peek() (T, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.rlock()
+	defer s.runlock()
+
+	return s.peek()
+}
 
+// peek returns the top item, or an *UnderflowError if the stack is empty.
+// Callers must hold the read lock.
+func (s *stack[T]) peek() (T, error) {
 	sz := len(s.items)
 	if sz == 0 {
+		s.stats.underflows.Add(1)
 		var zero T
-		return zero, ErrUnderflow
+		return zero, &UnderflowError{Name: s.name, Capacity: s.capacity}
 	}
 
 	idx := sz - 1
 
 	return s.items[idx], nil
 }
+
+func (s *stack[T]) PeekAt(depth int) (T, error) {
+	s.rlock()
+	defer s.runlock()
+
+	sz := len(s.items)
+	if depth < 0 || depth >= sz {
+		var zero T
+		return zero, fmt.Errorf("%w: depth %d, size %d", ErrIndexOutOfRange, depth, sz)
+	}
+
+	return s.items[sz-1-depth], nil
+}
+
+func (s *stack[T]) Find(pred func(T) bool) (T, bool) {
+	s.rlock()
+	defer s.runlock()
+
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if pred(s.items[i]) {
+			return s.items[i], true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (s *stack[T]) Clear() {
+	s.lock()
+	defer s.release()
+
+	if s.sealed() != nil {
+		return
+	}
+
+	s.clear()
+	s.broadcast()
+}
+
+func (s *stack[T]) DrainAll() []T {
+	s.lock()
+	defer s.release()
+
+	if s.frozen.Load() {
+		return nil
+	}
+
+	items := s.detach()
+	s.broadcast()
+
+	return items
+}
+
+// detach returns the items and gives the stack fresh, empty storage, so the
+// returned slice is no longer shared with the stack. Callers must hold the
+// write lock.
+func (s *stack[T]) detach() []T {
+	items := s.items
+	s.items = make([]T, 0)
+	s.fix()
+	s.reindex()
+
+	return items
+}
+
+func (s *stack[T]) RetainFunc(pred func(T) bool) int {
+	s.lock()
+	defer s.release()
+
+	if s.sealed() != nil {
+		return 0
+	}
+
+	removed := s.retain(pred)
+	if removed > 0 {
+		s.broadcast()
+	}
+
+	return removed
+}
+
+func (s *stack[T]) CompactFunc(eq func(a, b T) bool) int {
+	s.lock()
+	defer s.unlock()
+
+	if s.sealed() != nil {
+		return 0
+	}
+
+	n := len(s.items)
+	s.items = slices.CompactFunc(s.items, eq)
+	removed := n - len(s.items)
+	if removed > 0 {
+		s.reindex()
+		s.broadcast()
+	}
+
+	return removed
+}
+
+// retain removes, in place, the items for which pred returns false and returns
+// how many were removed. Callers must hold the write lock.
+func (s *stack[T]) retain(pred func(T) bool) int {
+	n := len(s.items)
+	s.items = slices.DeleteFunc(s.items, func(val T) bool { return !pred(val) })
+	if removed := n - len(s.items); removed > 0 {
+		s.reindex()
+		return removed
+	}
+
+	return 0
+}
+
+// fits reports whether vals can be pushed without exceeding the capacity or the
+// byte limit.
+// Callers must hold at least the read lock.
+func (s *stack[T]) fits(vals ...T) bool {
+	return s.within(len(s.items)+len(vals), s.bytes+s.sizeSum(vals))
+}
+
+// within reports whether a stack of n items totalling bytes would respect the
+// capacity and the byte limit.
+func (s *stack[T]) within(n, bytes int) bool {
+	return (s.capacity < 0 || n <= s.capacity) && (s.sizeOf == nil || bytes <= s.maxBytes)
+}
+
+// eachWithin reports whether each of vals could be held on its own.
+func (s *stack[T]) eachWithin(vals []T) bool {
+	for _, val := range vals {
+		if !s.within(1, s.sizeSum([]T{val})) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sizeSum returns the total size of vals, or zero if there is no byte limit.
+func (s *stack[T]) sizeSum(vals []T) int {
+	if s.sizeOf == nil {
+		return 0
+	}
+
+	total := 0
+	for _, val := range vals {
+		total += s.sizeOf(val)
+	}
+
+	return total
+}
+
+// overflowError returns the error for vals not fitting, describing whether
+// the capacity or the byte limit would be exceeded.
+func (s *stack[T]) overflowError(vals []T) error {
+	if n := len(s.items) + len(vals); s.capacity >= 0 && n > s.capacity {
+		return fmt.Errorf("%w: %d item(s) over capacity", ErrOverflow, n-s.capacity)
+	}
+
+	return fmt.Errorf("%w: %d byte(s) over limit", ErrOverflow, s.bytes+s.sizeSum(vals)-s.maxBytes)
+}
+
+// reserve makes room for vals, evicting the oldest items if the overflow
+// policy allows it. Returns false if the items cannot fit.
+// Callers must hold the write lock.
+func (s *stack[T]) reserve(vals ...T) bool {
+	if s.fits(vals...) {
+		return true
+	}
+
+	add := s.sizeSum(vals)
+	if s.policy != OverflowDropOldest || !s.within(len(vals), add) {
+		return false
+	}
+
+	excess, freed := 0, 0
+	for !s.within(len(s.items)-excess+len(vals), s.bytes-freed+add) {
+		freed += s.sizeSum(s.items[excess : excess+1])
+		excess++
+	}
+	if s.onEvict != nil {
+		s.evicted = append(s.evicted, s.items[:excess]...)
+	}
+	s.evict(excess)
+
+	return true
+}
+
+// evict removes the n oldest items from the bottom of the stack.
+// Callers must hold the write lock and ensure n does not exceed the size.
+func (s *stack[T]) evict(n int) {
+	if n == 0 {
+		return
+	}
+
+	kept := copy(s.items, s.items[n:])
+	clear(s.items[kept:])
+	s.items = s.items[:kept]
+	s.reindex()
+}
+
+// push appends val to the top of the stack.
+// Callers must hold the write lock and have checked fits or reserve.
+func (s *stack[T]) push(val T) {
+	if s.growth != nil && len(s.items) == cap(s.items) {
+		s.grow(max(s.growth(cap(s.items)), cap(s.items)+1))
+	}
+	s.items = append(s.items, val)
+	if s.sizeOf != nil {
+		s.bytes += s.sizeOf(val)
+	}
+	if s.unique != nil {
+		s.unique.add(val)
+	}
+
+	if s.less != nil {
+		lo, hi := val, val
+		if n := len(s.mins); n > 0 {
+			if s.less(s.mins[n-1], lo) {
+				lo = s.mins[n-1]
+			}
+			if s.less(hi, s.maxs[n-1]) {
+				hi = s.maxs[n-1]
+			}
+		}
+		s.mins = append(s.mins, lo)
+		s.maxs = append(s.maxs, hi)
+	}
+}
+
+// pop removes and returns the top item.
+// Callers must hold the write lock and ensure the stack is not empty.
+func (s *stack[T]) pop() T {
+	var zero T
+	idx := len(s.items) - 1
+
+	result := s.items[idx]
+	s.items[idx] = zero
+	s.items = s.items[:idx]
+	if s.sizeOf != nil {
+		s.bytes -= s.sizeOf(result)
+	}
+	if s.unique != nil {
+		s.unique.remove(result)
+	}
+
+	if s.less != nil {
+		s.mins[idx] = zero
+		s.mins = s.mins[:idx]
+		s.maxs[idx] = zero
+		s.maxs = s.maxs[:idx]
+	}
+
+	return result
+}
+
+// reindex rebuilds the bookkeeping derived from items after they were
+// replaced or modified in bulk. Callers must hold the write lock.
+func (s *stack[T]) reindex() {
+	if s.less == nil && s.sizeOf == nil && s.unique == nil {
+		return
+	}
+
+	items := s.items
+	s.items = s.items[:0]
+	s.bytes = 0
+	if s.unique != nil {
+		s.unique.reset()
+	}
+	clear(s.mins)
+	s.mins = s.mins[:0]
+	clear(s.maxs)
+	s.maxs = s.maxs[:0]
+	for _, val := range items {
+		s.push(val)
+	}
+}
+
+// clear removes all items, retaining storage up to the configured capacity.
+// Callers must hold the write lock.
+func (s *stack[T]) clear() {
+	if s.capacity >= 0 && cap(s.items) > s.capacity {
+		s.items = make([]T, 0, s.capacity)
+	} else {
+		clear(s.items)
+		s.items = s.items[:0]
+	}
+	s.reindex()
+}
+
+func (s *stack[T]) Name() string {
+	return s.name
+}
+
+func (s *stack[T]) Capacity() int {
+	s.rlock()
+	defer s.runlock()
+
+	return s.capacity
+}
+
+func (s *stack[T]) IsEmpty() bool {
+	s.rlock()
+	defer s.runlock()
+
+	return len(s.items) == 0
+}
+
+func (s *stack[T]) IsFull() bool {
+	s.rlock()
+	defer s.runlock()
+
+	return s.capacity >= 0 && len(s.items) >= s.capacity
+}
+
+func (s *stack[T]) ToSlice() []T {
+	s.rlock()
+	defer s.runlock()
+
+	result := make([]T, len(s.items))
+	copy(result, s.items)
+
+	return result
+}
+
+func (s *stack[T]) CopyInto(dst []T) int {
+	s.rlock()
+	defer s.runlock()
+
+	return copy(dst, s.items)
+}
+
+func (s *stack[T]) AppendTo(dst []T) []T {
+	s.rlock()
+	defer s.runlock()
+
+	return append(dst, s.items...)
+}
+
+func (s *stack[T]) Clone() Stack[T] {
+	s.rlock()
+	defer s.runlock()
+
+	clone := s.emptyCopy()
+	clone.items = append(clone.items, s.items...)
+	clone.reindex()
+
+	return clone
+}
+
+func (s *stack[T]) DeepClone(clone func(T) T) Stack[T] {
+	s.rlock()
+	defer s.runlock()
+
+	c := s.emptyCopy()
+	for _, item := range s.items {
+		c.items = append(c.items, clone(item))
+	}
+	c.reindex()
+
+	return c
+}
+
+// emptyCopy returns a new stack with the same configuration as s but no items.
+// Callers must hold at least the read lock.
+func (s *stack[T]) emptyCopy() *stack[T] {
+	c := &stack[T]{
+		unsynced:     s.unsynced,
+		name:         s.name,
+		capacity:     s.capacity,
+		policy:       s.policy,
+		items:        make([]T, 0),
+		less:         s.less,
+		onEvict:      s.onEvict,
+		observer:     s.observer,
+		encode:       s.encode,
+		maxBytes:     s.maxBytes,
+		encodeBinary: s.encodeBinary,
+		decodeBinary: s.decodeBinary,
+		sizeOf:       s.sizeOf,
+		fixed:        s.fixed,
+		signal:       s.signal,
+		onEmpty:      s.onEmpty,
+		growth:       s.growth,
+	}
+	if s.unique != nil {
+		c.unique = s.unique.fresh()
+		c.rejectDuplicates = s.rejectDuplicates
+	}
+	c.fix()
+
+	return c
+}
+
+func (s *stack[T]) TryPush(val T) bool {
+	s.lock()
+	defer s.release()
+
+	if s.sealed() != nil {
+		return false
+	}
+	if dup, _ := s.duplicate(val); dup {
+		return false
+	}
+	if !s.reserve(val) {
+		s.stats.overflows.Add(1)
+		return false
+	}
+
+	s.push(val)
+	s.didPush(val)
+	s.broadcast()
+
+	return true
+}
+
+// tryPush is TryPush without counting a failure as an overflow, for callers
+// that try several stacks in turn.
+func (s *stack[T]) tryPush(val T) bool {
+	s.lock()
+	defer s.release()
+
+	if s.sealed() != nil || !s.reserve(val) {
+		return false
+	}
+
+	s.push(val)
+	s.didPush(val)
+	s.broadcast()
+
+	return true
+}
+
+func (s *stack[T]) TryPop() (T, bool) {
+	val, ok := s.tryPop()
+	if !ok && s.sealed() == nil {
+		s.stats.underflows.Add(1)
+	}
+
+	return val, ok
+}
+
+// tryPop is TryPop without counting a failure as an underflow, for callers
+// that try several stacks in turn.
+func (s *stack[T]) tryPop() (T, bool) {
+	s.lock()
+	defer s.release()
+
+	if s.sealed() != nil || len(s.items) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	result := s.pop()
+	s.didPop(result)
+	s.broadcast()
+
+	return result, true
+}
+
+func (s *stack[T]) PeekN(n int) ([]T, error) {
+	s.rlock()
+	defer s.runlock()
+
+	sz := len(s.items)
+	if n < 0 {
+		return nil, fmt.Errorf("%w: count %d, size %d", ErrIndexOutOfRange, n, sz)
+	}
+	if n > sz {
+		s.stats.underflows.Add(1)
+		return nil, &UnderflowError{Name: s.name, Capacity: s.capacity, Size: sz}
+	}
+
+	result := make([]T, n)
+	for i := range result {
+		result[i] = s.items[sz-1-i]
+	}
+
+	return result, nil
+}
+
+func (s *stack[T]) ResetWithCapacity(capacity int) error {
+	if capacity < UnlimitedCapacity {
+		return ErrInvalidCapacity
+	}
+
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+
+	s.capacity = capacity
+	s.clear()
+	s.fix()
+	s.broadcast()
+
+	return nil
+}
+
+func (s *stack[T]) SetCapacity(capacity int) (int, error) {
+	if capacity < UnlimitedCapacity {
+		return 0, ErrInvalidCapacity
+	}
+
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return 0, err
+	}
+
+	s.capacity = capacity
+
+	dropped := 0
+	if capacity >= 0 && len(s.items) > capacity {
+		dropped = len(s.items) - capacity
+		s.evict(dropped)
+	}
+	s.fix()
+	s.broadcast()
+
+	return dropped, nil
+}
+
+func (s *stack[T]) Grow(n int) {
+	if n < 0 {
+		panic("cannot grow by a negative number of items")
+	}
+
+	s.lock()
+	defer s.unlock()
+
+	s.grow(len(s.items) + n)
+}
+
+// grow ensures the storage has room for want items in total, limited to the
+// capacity. Callers must hold the write lock.
+func (s *stack[T]) grow(want int) {
+	if s.capacity >= 0 && want > s.capacity {
+		want = s.capacity
+	}
+	if want <= cap(s.items) {
+		return
+	}
+
+	items := make([]T, len(s.items), want)
+	copy(items, s.items)
+	s.items = items
+}
+
+func (s *stack[T]) ShrinkToFit() {
+	s.lock()
+	defer s.unlock()
+
+	if s.fixed || cap(s.items) == len(s.items) {
+		return
+	}
+
+	items := make([]T, len(s.items))
+	copy(items, s.items)
+	s.items = items
+}
+
+func (s *stack[T]) Filter(pred func(T) bool) Stack[T] {
+	s.rlock()
+	items := make([]T, len(s.items))
+	copy(items, s.items)
+	result := s.emptyCopy()
+	s.runlock()
+
+	for _, item := range items {
+		if pred(item) {
+			result.push(item)
+		}
+	}
+
+	return result
+}
+
+// String returns a readable representation of the stack, such as
+// "Stack[3/10]: [1 2 3]", listing items from bottom to top.
+// The capacity of an unlimited stack is shown as ∞, and the name set with
+// WithName, if any, follows "Stack", as in `Stack "jobs"[3/10]: [1 2 3]`.
+func (s *stack[T]) String() string {
+	s.rlock()
+	defer s.runlock()
+
+	return formatStack(s.name, s.items, s.capacity)
+}
+
+// formatStack formats the name, items and capacity of a stack for String.
+func formatStack[T any](name string, items []T, capacity int) string {
+	limit := "∞"
+	if capacity >= 0 {
+		limit = strconv.Itoa(capacity)
+	}
+	if name != "" {
+		name = " " + strconv.Quote(name)
+	}
+
+	return fmt.Sprintf("Stack%s[%d/%s]: %v", name, len(items), limit, items)
+}
+
+func (s *stack[T]) Reverse() {
+	s.lock()
+	defer s.unlock()
+
+	if s.sealed() != nil {
+		return
+	}
+
+	slices.Reverse(s.items)
+	s.reindex()
+	s.broadcast()
+}
+
+func (s *stack[T]) Swap() error {
+	s.lock()
+	defer s.unlock()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+
+	if len(s.items) < 2 {
+		s.stats.underflows.Add(1)
+		return ErrUnderflow
+	}
+
+	top, next := s.pop(), s.pop()
+	s.push(top)
+	s.push(next)
+	s.broadcast()
+
+	return nil
+}
+
+func (s *stack[T]) SwapAt(i, j int) error {
+	s.lock()
+	defer s.unlock()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	sz := len(s.items)
+	for _, depth := range []int{i, j} {
+		if depth < 0 || depth >= sz {
+			return fmt.Errorf("%w: depth %d, size %d", ErrIndexOutOfRange, depth, sz)
+		}
+	}
+
+	if i != j {
+		a, b := sz-1-i, sz-1-j
+		s.items[a], s.items[b] = s.items[b], s.items[a]
+		s.reindex()
+		s.broadcast()
+	}
+
+	return nil
+}
+
+func (s *stack[T]) Dup() error {
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if len(s.items) == 0 {
+		s.stats.underflows.Add(1)
+		return ErrUnderflow
+	}
+
+	top := s.items[len(s.items)-1]
+	if dup, err := s.duplicate(top); dup {
+		return err
+	}
+	if !s.reserve(top) {
+		s.stats.overflows.Add(1)
+		return ErrOverflow
+	}
+
+	s.push(top)
+	s.didPush(top)
+	s.broadcast()
+
+	return nil
+}
+
+func (s *stack[T]) UpdateTop(fn func(top *T) error) error {
+	s.lock()
+	defer s.unlock()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if len(s.items) == 0 {
+		s.stats.underflows.Add(1)
+		return &UnderflowError{Name: s.name, Capacity: s.capacity}
+	}
+
+	return s.updateTop(fn)
+}
+
+// updateTop applies fn to a copy of the top item and replaces the top item with
+// the result, keeping the original if fn fails or the result exceeds the byte
+// limit. Callers must hold the write lock and ensure the stack is not empty.
+func (s *stack[T]) updateTop(fn func(top *T) error) error {
+	val := s.items[len(s.items)-1]
+	if err := fn(&val); err != nil {
+		return err
+	}
+
+	old := s.pop()
+	if !s.fits(val) {
+		err := s.overflowError([]T{val})
+		s.push(old)
+		return err
+	}
+	s.push(val)
+	s.broadcast()
+
+	return nil
+}
+
+func (s *stack[T]) PopUntil(pred func(T) bool) ([]T, error) {
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return nil, err
+	}
+
+	var result []T
+	for len(s.items) > 0 && pred(s.items[len(s.items)-1]) {
+		val := s.pop()
+		s.didPop(val)
+		result = append(result, val)
+	}
+	if len(result) > 0 {
+		s.broadcast()
+	}
+
+	return result, nil
+}
+
+func (s *stack[T]) SplitAt(n int) (bottom, top Stack[T], err error) {
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return nil, nil, err
+	}
+	if n < 0 {
+		return nil, nil, fmt.Errorf("%w: index %d, size %d", ErrIndexOutOfRange, n, len(s.items))
+	}
+	if n > len(s.items) {
+		s.stats.underflows.Add(1)
+		return nil, nil, ErrUnderflow
+	}
+
+	upper := s.emptyCopy()
+	upper.items = append(upper.items, s.items[n:]...)
+	upper.reindex()
+	upper.trackDepth()
+
+	clear(s.items[n:])
+	s.items = s.items[:n]
+	s.reindex()
+	s.broadcast()
+
+	return s, upper, nil
+}
+
+func (s *stack[T]) PopOr(def T) T {
+	s.lock()
+	defer s.release()
+
+	if s.sealed() != nil || len(s.items) == 0 {
+		return def
+	}
+
+	result := s.pop()
+	s.didPop(result)
+	s.broadcast()
+
+	return result
+}
+
+func (s *stack[T]) PeekOr(def T) T {
+	s.rlock()
+	defer s.runlock()
+
+	if len(s.items) == 0 {
+		return def
+	}
+
+	return s.items[len(s.items)-1]
+}
+
+func (s *stack[T]) RemainingCapacity() int {
+	s.rlock()
+	defer s.runlock()
+
+	if s.capacity < 0 {
+		return UnlimitedCapacity
+	}
+
+	return s.capacity - len(s.items)
+}
+
+func (s *stack[T]) Utilization() float64 {
+	s.rlock()
+	defer s.runlock()
+
+	return utilization(len(s.items), s.capacity)
+}
+
+// utilization returns size as a fraction of capacity for Utilization.
+func utilization(size, capacity int) float64 {
+	switch {
+	case capacity < 0:
+		return UnlimitedCapacity
+	case capacity == 0:
+		return 1
+	default:
+		return float64(size) / float64(capacity)
+	}
+}
+
+func (s *stack[T]) PushBottom(val T) error {
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if dup, err := s.duplicate(val); dup {
+		return err
+	}
+	if !s.fits(val) {
+		s.stats.overflows.Add(1)
+		return &OverflowError{Name: s.name, Capacity: s.capacity, Size: len(s.items)}
+	}
+
+	s.items = slices.Insert(s.items, 0, val)
+	s.reindex()
+	s.didPush(val)
+	s.broadcast()
+
+	return nil
+}
+
+func (s *stack[T]) PopBottom() (T, error) {
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		var zero T
+		return zero, err
+	}
+	if len(s.items) == 0 {
+		s.stats.underflows.Add(1)
+		var zero T
+		return zero, &UnderflowError{Name: s.name, Capacity: s.capacity}
+	}
+
+	result := s.items[0]
+	s.evict(1)
+	s.didPop(result)
+	s.broadcast()
+
+	return result, nil
+}
+
+func (s *stack[T]) Rotate(n int) error {
+	s.lock()
+	defer s.unlock()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+	if rotate(s.items, n) {
+		s.reindex()
+		s.broadcast()
+	}
+
+	return nil
+}
+
+// rotate moves the top item of items to the bottom n times, or the bottom item
+// to the top -n times if n is negative, and reports whether items changed.
+func rotate[T any](items []T, n int) bool {
+	if len(items) == 0 {
+		return false
+	}
+
+	k := n % len(items)
+	if k < 0 {
+		k += len(items)
+	}
+	if k == 0 {
+		return false
+	}
+
+	slices.Reverse(items)
+	slices.Reverse(items[:k])
+	slices.Reverse(items[k:])
+
+	return true
+}
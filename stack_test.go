@@ -1,7 +1,12 @@
 package stack
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -174,6 +179,98 @@ func TestCapacityOverflow(t *testing.T) {
 	}
 }
 
+func TestErrorTypes(t *testing.T) {
+	t.Run("overflow", func(t *testing.T) {
+		s := New[int](WithCapacity[int](2), WithItems([]int{1, 2}))
+
+		err := s.Push(3)
+		if !errors.Is(err, ErrOverflow) {
+			t.Fatalf("Push() on full stack error = %v, want ErrOverflow", err)
+		}
+
+		var overflow *OverflowError
+		if !errors.As(err, &overflow) {
+			t.Fatalf("Push() error %T does not unwrap to *OverflowError", err)
+		}
+		if overflow.Capacity != 2 || overflow.Size != 2 {
+			t.Errorf("OverflowError = %+v, want Capacity 2, Size 2", *overflow)
+		}
+		if got, want := err.Error(), "stack overflow (size 2, capacity 2)"; got != want {
+			t.Errorf("Error() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("underflow", func(t *testing.T) {
+		s := New[int](WithCapacity[int](5), WithItems([]int{1}))
+
+		_, err := s.PeekN(3)
+		var underflow *UnderflowError
+		if !errors.As(err, &underflow) || !errors.Is(err, ErrUnderflow) {
+			t.Fatalf("PeekN() error = %v, want *UnderflowError wrapping ErrUnderflow", err)
+		}
+		if underflow.Capacity != 5 || underflow.Size != 1 {
+			t.Errorf("UnderflowError = %+v, want Capacity 5, Size 1", *underflow)
+		}
+
+		_, _ = s.Pop()
+		for name, op := range map[string]func() error{
+			"Pop":  func() error { _, err := s.Pop(); return err },
+			"Peek": func() error { _, err := s.Peek(); return err },
+		} {
+			if err := op(); !errors.As(err, &underflow) || underflow.Size != 0 {
+				t.Errorf("%s() on empty stack error = %v, want *UnderflowError with Size 0", name, err)
+			}
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		s := NewSharded[int](2, WithCapacity[int](2), WithItems([]int{1, 2}))
+
+		var overflow *OverflowError
+		if err := s.Push(3); !errors.As(err, &overflow) || overflow.Size != 2 || overflow.Capacity != 2 {
+			t.Errorf("sharded Push() on full stack error = %v, want *OverflowError with Size 2, Capacity 2", err)
+		}
+
+		s.Clear()
+		var underflow *UnderflowError
+		if _, err := s.Pop(); !errors.As(err, &underflow) || underflow.Capacity != 2 {
+			t.Errorf("sharded Pop() on empty stack error = %v, want *UnderflowError with Capacity 2", err)
+		}
+	})
+}
+
+func TestPopOrPeekOr(t *testing.T) {
+	for name, s := range map[string]Stack[int]{
+		"plain":   New[int](WithItems([]int{1, 2})),
+		"sharded": NewSharded[int](2, WithItems([]int{1, 2})),
+	} {
+		t.Run(name, func(t *testing.T) {
+			top, _ := s.Peek()
+			if got := s.PeekOr(-1); got != top {
+				t.Errorf("PeekOr(-1) = %d, want %d", got, top)
+			}
+			// Sharded stacks may pop from any shard, so only check an item was popped.
+			if got := s.PopOr(-1); got != 1 && got != 2 {
+				t.Errorf("PopOr(-1) = %d, want an item from the stack", got)
+			}
+			if size := s.Size(); size != 1 {
+				t.Errorf("Size() after PopOr() = %d, want 1", size)
+			}
+
+			_ = s.PopOr(-1)
+			if got := s.PopOr(-1); got != -1 {
+				t.Errorf("PopOr(-1) on empty stack = %d, want -1", got)
+			}
+			if got := s.PeekOr(-2); got != -2 {
+				t.Errorf("PeekOr(-2) on empty stack = %d, want -2", got)
+			}
+			if st := s.Stats(); st.Underflows != 0 || st.Pops != 2 {
+				t.Errorf("Stats() = %+v, want 2 pops and no underflows", st)
+			}
+		})
+	}
+}
+
 func TestUnlimitedCapacity(t *testing.T) {
 	s := New[int]()
 
@@ -326,13 +423,1686 @@ func TestEdgeCases(t *testing.T) {
 	})
 }
 
-// Benchmark tests
-func BenchmarkPush(b *testing.B) {
-	s := New[int]()
-	b.ResetTimer()
+func TestClear(t *testing.T) {
+	t.Run("removes all items", func(t *testing.T) {
+		s := New[int]()
+		for i := 0; i < 10; i++ {
+			_ = s.Push(i)
+		}
 
-	for i := 0; i < b.N; i++ {
-		_ = s.Push(i)
+		s.Clear()
+
+		if size := s.Size(); size != 0 {
+			t.Errorf("Size after Clear() = %d, want 0", size)
+		}
+
+		_, err := s.Pop()
+		if !errors.Is(err, ErrUnderflow) {
+			t.Errorf("Pop() after Clear() error = %v, want ErrUnderflow", err)
+		}
+	})
+
+	t.Run("empty stack", func(t *testing.T) {
+		s := New[int]()
+		s.Clear()
+
+		if size := s.Size(); size != 0 {
+			t.Errorf("Size after Clear() on empty stack = %d, want 0", size)
+		}
+	})
+
+	t.Run("retains storage", func(t *testing.T) {
+		s := newStack[int]()
+		for i := 0; i < 100; i++ {
+			_ = s.Push(i)
+		}
+		before := cap(s.items)
+
+		s.Clear()
+
+		if after := cap(s.items); after != before {
+			t.Errorf("cap(items) after Clear() = %d, want %d", after, before)
+		}
+	})
+
+	t.Run("storage bounded by capacity", func(t *testing.T) {
+		s := newStack[int](WithCapacity[int](5))
+		for i := 0; i < 5; i++ {
+			_ = s.Push(i)
+		}
+
+		s.Clear()
+
+		if c := cap(s.items); c > 5 {
+			t.Errorf("cap(items) after Clear() = %d, want <= 5", c)
+		}
+
+		for i := 0; i < 5; i++ {
+			if err := s.Push(i); err != nil {
+				t.Errorf("Push(%d) after Clear() error = %v, want nil", i, err)
+			}
+		}
+	})
+}
+
+func TestCapacity(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option[int]
+		want int
+	}{
+		{name: "default", opts: nil, want: UnlimitedCapacity},
+		{name: "unlimited", opts: []Option[int]{WithCapacity[int](UnlimitedCapacity)}, want: UnlimitedCapacity},
+		{name: "zero", opts: []Option[int]{WithCapacity[int](0)}, want: 0},
+		{name: "bounded", opts: []Option[int]{WithCapacity[int](100)}, want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New[int](tt.opts...)
+			if got := s.Capacity(); got != tt.want {
+				t.Errorf("Capacity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemainingCapacity(t *testing.T) {
+	s := New[int](WithCapacity[int](3))
+	if got := s.RemainingCapacity(); got != 3 {
+		t.Errorf("RemainingCapacity() on empty stack = %d, want 3", got)
+	}
+
+	_ = s.PushMany(1, 2, 3)
+	if got := s.RemainingCapacity(); got != 0 {
+		t.Errorf("RemainingCapacity() on full stack = %d, want 0", got)
+	}
+
+	if got := New[int]().RemainingCapacity(); got != UnlimitedCapacity {
+		t.Errorf("RemainingCapacity() on unlimited stack = %d, want UnlimitedCapacity", got)
+	}
+
+	sh := NewSharded[int](3, WithCapacity[int](10), WithItems([]int{1, 2, 3, 4}))
+	if got := sh.RemainingCapacity(); got != 6 {
+		t.Errorf("sharded RemainingCapacity() = %d, want 6", got)
+	}
+}
+
+func TestIsEmptyIsFull(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		s := New[int]()
+		if !s.IsEmpty() {
+			t.Error("IsEmpty() on new stack = false, want true")
+		}
+		if s.IsFull() {
+			t.Error("IsFull() on new unlimited stack = true, want false")
+		}
+
+		for i := 0; i < 1000; i++ {
+			_ = s.Push(i)
+		}
+
+		if s.IsEmpty() {
+			t.Error("IsEmpty() after pushes = true, want false")
+		}
+		if s.IsFull() {
+			t.Error("IsFull() on unlimited stack = true, want false")
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		s := New[int](WithCapacity[int](2))
+		_ = s.Push(1)
+		if s.IsFull() {
+			t.Error("IsFull() at 1/2 = true, want false")
+		}
+
+		_ = s.Push(2)
+		if !s.IsFull() {
+			t.Error("IsFull() at 2/2 = false, want true")
+		}
+
+		_, _ = s.Pop()
+		if s.IsFull() {
+			t.Error("IsFull() after pop = true, want false")
+		}
+	})
+
+	t.Run("zero capacity", func(t *testing.T) {
+		s := New[int](WithCapacity[int](0))
+		if !s.IsEmpty() {
+			t.Error("IsEmpty() = false, want true")
+		}
+		if !s.IsFull() {
+			t.Error("IsFull() on zero capacity stack = false, want true")
+		}
+	})
+}
+
+func TestPushMany(t *testing.T) {
+	t.Run("pushes in order", func(t *testing.T) {
+		s := New[int]()
+		if err := s.PushMany(1, 2, 3); err != nil {
+			t.Fatalf("PushMany() error = %v, want nil", err)
+		}
+
+		for want := 3; want >= 1; want-- {
+			val, err := s.Pop()
+			if err != nil {
+				t.Errorf("Pop() error = %v, want nil", err)
+			}
+			if val != want {
+				t.Errorf("Pop() = %d, want %d", val, want)
+			}
+		}
+	})
+
+	t.Run("no items", func(t *testing.T) {
+		s := New[int](WithCapacity[int](0))
+		if err := s.PushMany(); err != nil {
+			t.Errorf("PushMany() with no items error = %v, want nil", err)
+		}
+	})
+
+	t.Run("fills to capacity", func(t *testing.T) {
+		s := New[int](WithCapacity[int](3))
+		_ = s.Push(0)
+		if err := s.PushMany(1, 2); err != nil {
+			t.Errorf("PushMany() error = %v, want nil", err)
+		}
+		if size := s.Size(); size != 3 {
+			t.Errorf("Size after PushMany() = %d, want 3", size)
+		}
+	})
+
+	t.Run("all or nothing", func(t *testing.T) {
+		s := New[int](WithCapacity[int](3))
+		_ = s.Push(0)
+
+		err := s.PushMany(1, 2, 3, 4)
+		if !errors.Is(err, ErrOverflow) {
+			t.Errorf("PushMany() exceeding capacity error = %v, want ErrOverflow", err)
+		}
+		if size := s.Size(); size != 1 {
+			t.Errorf("Size after rejected PushMany() = %d, want 1", size)
+		}
+	})
+}
+
+func TestToSlice(t *testing.T) {
+	t.Run("empty stack", func(t *testing.T) {
+		s := New[int]()
+		got := s.ToSlice()
+		if got == nil {
+			t.Error("ToSlice() on empty stack = nil, want non-nil")
+		}
+		if len(got) != 0 {
+			t.Errorf("len(ToSlice()) = %d, want 0", len(got))
+		}
+	})
+
+	t.Run("bottom to top", func(t *testing.T) {
+		s := New[int]()
+		_ = s.PushMany(1, 2, 3)
+
+		got := s.ToSlice()
+		want := []int{1, 2, 3}
+		if len(got) != len(want) {
+			t.Fatalf("ToSlice() = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("ToSlice() = %v, want %v", got, want)
+				break
+			}
+		}
+	})
+
+	t.Run("returns a copy", func(t *testing.T) {
+		s := New[int]()
+		_ = s.PushMany(1, 2, 3)
+
+		got := s.ToSlice()
+		got[2] = 42
+
+		if val, _ := s.Peek(); val != 3 {
+			t.Errorf("Peek() after mutating ToSlice() result = %d, want 3", val)
+		}
+	})
+}
+
+func TestClone(t *testing.T) {
+	s := New[int](WithCapacity[int](5))
+	_ = s.PushMany(1, 2, 3)
+
+	c := s.Clone()
+
+	if got := c.Capacity(); got != 5 {
+		t.Errorf("Clone().Capacity() = %d, want 5", got)
+	}
+	if got := c.Size(); got != 3 {
+		t.Errorf("Clone().Size() = %d, want 3", got)
+	}
+
+	// Mutating the clone must not affect the original
+	_, _ = c.Pop()
+	_ = c.Push(42)
+	if val, _ := s.Peek(); val != 3 {
+		t.Errorf("original Peek() after mutating clone = %d, want 3", val)
+	}
+
+	// Mutating the original must not affect the clone
+	s.Clear()
+	if val, _ := c.Peek(); val != 42 {
+		t.Errorf("clone Peek() after clearing original = %d, want 42", val)
+	}
+	if got := c.Size(); got != 3 {
+		t.Errorf("clone Size() after clearing original = %d, want 3", got)
+	}
+}
+
+func TestDeepClone(t *testing.T) {
+	s := New[[]int](WithCapacity[[]int](5), WithItems([][]int{{1}, {2, 3}}))
+
+	c := s.DeepClone(slices.Clone[[]int])
+	if got := c.Capacity(); got != 5 {
+		t.Errorf("DeepClone().Capacity() = %d, want 5", got)
+	}
+
+	top, _ := c.Peek()
+	top[0] = 99
+	if orig, _ := s.Peek(); orig[0] != 2 {
+		t.Errorf("original top after mutating deep clone = %v, want [2 3]", orig)
+	}
+
+	shallow := s.Clone()
+	top, _ = shallow.Peek()
+	top[0] = 99
+	if orig, _ := s.Peek(); orig[0] != 99 {
+		t.Errorf("original top after mutating shallow clone = %v, want [99 3]", orig)
+	}
+}
+
+func TestWithItems(t *testing.T) {
+	t.Run("seeds bottom to top", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2, 3}))
+		if size := s.Size(); size != 3 {
+			t.Errorf("Size() = %d, want 3", size)
+		}
+
+		for want := 3; want >= 1; want-- {
+			if val, _ := s.Pop(); val != want {
+				t.Errorf("Pop() = %d, want %d", val, want)
+			}
+		}
+	})
+
+	t.Run("copies input", func(t *testing.T) {
+		items := []int{1, 2, 3}
+		s := New[int](WithItems(items))
+		items[2] = 42
+
+		if val, _ := s.Peek(); val != 3 {
+			t.Errorf("Peek() after mutating input = %d, want 3", val)
+		}
+	})
+
+	t.Run("capacity applied after items", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2}), WithCapacity[int](2))
+		if err := s.Push(3); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() on full seeded stack error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("exceeds capacity (should panic)", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("New() with too many seeded items should panic, but it didn't")
+			}
+		}()
+
+		New[int](WithCapacity[int](2), WithItems([]int{1, 2, 3}))
+	})
+}
+
+func TestWithInitialCapacity(t *testing.T) {
+	t.Run("pre-allocates without limiting", func(t *testing.T) {
+		s := newStack[int](WithInitialCapacity[int](100))
+
+		if c := cap(s.items); c < 100 {
+			t.Errorf("cap(items) = %d, want >= 100", c)
+		}
+		if s.Capacity() != UnlimitedCapacity {
+			t.Errorf("Capacity() = %d, want UnlimitedCapacity", s.Capacity())
+		}
+		for i := 0; i < 200; i++ {
+			if err := s.Push(i); err != nil {
+				t.Fatalf("Push(%d) error = %v, want nil", i, err)
+			}
+		}
+	})
+
+	t.Run("with items", func(t *testing.T) {
+		s := newStack[int](WithInitialCapacity[int](50), WithItems([]int{1, 2}))
+
+		if c := cap(s.items); c < 50 {
+			t.Errorf("cap(items) = %d, want >= 50", c)
+		}
+		if !slices.Equal(s.ToSlice(), []int{1, 2}) {
+			t.Errorf("ToSlice() = %v, want [1 2]", s.ToSlice())
+		}
+	})
+
+	t.Run("limited by capacity", func(t *testing.T) {
+		s := newStack[int](WithInitialCapacity[int](100), WithCapacity[int](10))
+
+		if c := cap(s.items); c != 10 {
+			t.Errorf("cap(items) = %d, want 10", c)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		s := NewSharded[int](4, WithInitialCapacity[int](100)).(*sharded[int])
+
+		for i, sh := range s.shards {
+			if c := cap(sh.items); c < 25 {
+				t.Errorf("cap(shards[%d].items) = %d, want >= 25", i, c)
+			}
+		}
+	})
+
+	t.Run("negative (should panic)", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("WithInitialCapacity(-1) should panic, but it didn't")
+			}
+		}()
+
+		New[int](WithInitialCapacity[int](-1))
+	})
+}
+
+func TestWithMaxBytes(t *testing.T) {
+	size := func(s string) int { return len(s) }
+
+	t.Run("push and pop maintain total", func(t *testing.T) {
+		s := New[string](WithMaxBytes(5, size))
+
+		if err := s.Push("abc"); err != nil {
+			t.Fatalf("Push() error = %v, want nil", err)
+		}
+		if err := s.Push("def"); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() over byte limit error = %v, want ErrOverflow", err)
+		}
+		if err := s.Push("de"); err != nil {
+			t.Errorf("Push() up to byte limit error = %v, want nil", err)
+		}
+
+		_, _ = s.Pop()
+		_, _ = s.Pop()
+		if err := s.Push("abcde"); err != nil {
+			t.Errorf("Push() after popping everything error = %v, want nil", err)
+		}
+		if s.TryPush("x") {
+			t.Error("TryPush() over byte limit = true, want false")
+		}
+	})
+
+	t.Run("push many", func(t *testing.T) {
+		s := New[string](WithMaxBytes(5, size), WithItems([]string{"ab"}))
+
+		err := s.PushMany("cd", "ef")
+		if !errors.Is(err, ErrOverflow) {
+			t.Fatalf("PushMany() over byte limit error = %v, want ErrOverflow", err)
+		}
+		if !strings.Contains(err.Error(), "1 byte(s) over limit") {
+			t.Errorf("PushMany() error = %q, want it to mention the excess bytes", err)
+		}
+		if s.Size() != 1 {
+			t.Errorf("Size() after failed PushMany() = %d, want 1", s.Size())
+		}
+	})
+
+	t.Run("bulk changes", func(t *testing.T) {
+		s := New[string](WithMaxBytes(4, size), WithItems([]string{"ab", "cd"}))
+
+		s.Clear()
+		if err := s.PushMany("abcd"); err != nil {
+			t.Errorf("PushMany() after Clear() error = %v, want nil", err)
+		}
+
+		snap := New[string](WithItems([]string{"abcde"})).Snapshot()
+		if err := s.Restore(snap); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Restore() over byte limit error = %v, want ErrOverflow", err)
+		}
+		if err := json.Unmarshal([]byte(`["abc","de"]`), s); !errors.Is(err, ErrOverflow) {
+			t.Errorf("UnmarshalJSON() over byte limit error = %v, want ErrOverflow", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []string{"abcd"}) {
+			t.Errorf("ToSlice() after failed bulk changes = %v, want [abcd]", got)
+		}
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		var evicted []string
+		s := New[string](
+			WithMaxBytes(6, size),
+			WithOverflowPolicy[string](OverflowDropOldest),
+			WithEvictionHandler(func(v string) { evicted = append(evicted, v) }),
+			WithItems([]string{"a", "bb", "ccc"}),
+		)
+
+		if err := s.Push("dddd"); err != nil {
+			t.Fatalf("Push() error = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []string{"dddd"}) {
+			t.Errorf("ToSlice() = %v, want [dddd]", got)
+		}
+		if !slices.Equal(evicted, []string{"a", "bb", "ccc"}) {
+			t.Errorf("evicted = %v, want [a bb ccc]", evicted)
+		}
+
+		evicted = nil
+		if err := s.PushMany("ee", "fff", "g"); err != nil {
+			t.Fatalf("PushMany() error = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []string{"ee", "fff", "g"}) {
+			t.Errorf("ToSlice() = %v, want [ee fff g]", got)
+		}
+
+		if err := s.Push("hhhhhhh"); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() of item larger than the limit error = %v, want ErrOverflow", err)
+		}
+		if err := s.PushMany("i", "jjjjjjj"); !errors.Is(err, ErrOverflow) {
+			t.Errorf("PushMany() with item larger than the limit error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("combined with capacity", func(t *testing.T) {
+		s := New[string](WithMaxBytes(100, size), WithCapacity[string](2))
+
+		_ = s.PushMany("a", "b")
+		if err := s.Push("c"); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() over capacity error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("seeded over limit (should panic)", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("New() with seeded items over the byte limit should panic, but it didn't")
+			}
+		}()
+
+		New[string](WithMaxBytes(2, size), WithItems([]string{"abc"}))
+	})
+
+	t.Run("sharded (should panic)", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("NewSharded() with WithMaxBytes should panic, but it didn't")
+			}
+		}()
+
+		NewSharded[string](2, WithMaxBytes(2, size))
+	})
+}
+
+func TestNewFromSlice(t *testing.T) {
+	t.Run("bottom to top", func(t *testing.T) {
+		items := []int{1, 2, 3}
+		s, err := NewFromSlice(items, WithCapacity[int](3))
+		if err != nil {
+			t.Fatalf("NewFromSlice() error = %v, want nil", err)
+		}
+
+		items[2] = 42
+		if val, _ := s.Pop(); val != 3 {
+			t.Errorf("Pop() = %d, want 3", val)
+		}
+		if c := s.Capacity(); c != 3 {
+			t.Errorf("Capacity() = %d, want 3", c)
+		}
+	})
+
+	t.Run("nil slice", func(t *testing.T) {
+		s, err := NewFromSlice[int](nil)
+		if err != nil || !s.IsEmpty() {
+			t.Errorf("NewFromSlice(nil) = %v, %v, want empty stack", s, err)
+		}
+	})
+
+	t.Run("over capacity", func(t *testing.T) {
+		s, err := NewFromSlice([]int{1, 2, 3}, WithCapacity[int](2))
+		if !errors.Is(err, ErrOverflow) {
+			t.Errorf("NewFromSlice() over capacity error = %v, want ErrOverflow", err)
+		}
+		if s != nil {
+			t.Errorf("NewFromSlice() over capacity returned %v, want nil", s)
+		}
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		s, err := NewFromSlice([]int{1}, WithCapacity[int](-5))
+		if !errors.Is(err, ErrInvalidCapacity) {
+			t.Errorf("NewFromSlice() with invalid capacity error = %v, want ErrInvalidCapacity", err)
+		}
+		if s != nil {
+			t.Errorf("NewFromSlice() with invalid capacity returned %v, want nil", s)
+		}
+	})
+
+	t.Run("ignores WithItems", func(t *testing.T) {
+		s, err := NewFromSlice([]int{1}, WithCapacity[int](1), WithItems([]int{7, 8, 9}))
+		if err != nil {
+			t.Fatalf("NewFromSlice() error = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{1}) {
+			t.Errorf("ToSlice() = %v, want [1]", got)
+		}
+	})
+}
+
+func TestTryPush(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		s := New[int]()
+		for i := 0; i < 1000; i++ {
+			if !s.TryPush(i) {
+				t.Fatalf("TryPush(%d) on unlimited stack = false, want true", i)
+			}
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		s := New[int](WithCapacity[int](2))
+		if !s.TryPush(1) || !s.TryPush(2) {
+			t.Fatal("TryPush() below capacity = false, want true")
+		}
+		if s.TryPush(3) {
+			t.Error("TryPush() on full stack = true, want false")
+		}
+		if val, _ := s.Peek(); val != 2 {
+			t.Errorf("Peek() after rejected TryPush() = %d, want 2", val)
+		}
+	})
+}
+
+func TestTryPop(t *testing.T) {
+	s := New[int]()
+
+	val, ok := s.TryPop()
+	if ok {
+		t.Error("TryPop() on empty stack ok = true, want false")
+	}
+	if val != 0 {
+		t.Errorf("TryPop() on empty stack value = %d, want 0 (zero value)", val)
+	}
+
+	_ = s.PushMany(1, 2, 3)
+
+	var got []int
+	for {
+		v, ok := s.TryPop()
+		if !ok {
+			break
+		}
+		got = append(got, v)
+	}
+
+	if len(got) != 3 || got[0] != 3 || got[1] != 2 || got[2] != 1 {
+		t.Errorf("TryPop() sequence = %v, want [3 2 1]", got)
+	}
+}
+
+func TestPeekN(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3}))
+
+	tests := []struct {
+		n       int
+		want    []int
+		wantErr error
+	}{
+		{n: 0, want: []int{}},
+		{n: 1, want: []int{3}},
+		{n: 2, want: []int{3, 2}},
+		{n: 3, want: []int{3, 2, 1}},
+		{n: 4, want: nil, wantErr: ErrUnderflow},
+	}
+
+	for _, tt := range tests {
+		got, err := s.PeekN(tt.n)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("PeekN(%d) error = %v, want %v", tt.n, err, tt.wantErr)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("PeekN(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+
+	if size := s.Size(); size != 3 {
+		t.Errorf("Size after PeekN() = %d, want 3", size)
+	}
+
+	if _, err := s.PeekN(-1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("PeekN(-1) error = %v, want ErrIndexOutOfRange", err)
+	}
+}
+
+func TestResetWithCapacity(t *testing.T) {
+	t.Run("clears and changes capacity", func(t *testing.T) {
+		s := New[int](WithCapacity[int](5), WithItems([]int{1, 2, 3, 4}))
+
+		if err := s.ResetWithCapacity(2); err != nil {
+			t.Fatalf("ResetWithCapacity(2) error = %v, want nil", err)
+		}
+		if !s.IsEmpty() {
+			t.Errorf("Size after ResetWithCapacity() = %d, want 0", s.Size())
+		}
+		if got := s.Capacity(); got != 2 {
+			t.Errorf("Capacity after ResetWithCapacity(2) = %d, want 2", got)
+		}
+		if err := s.PushMany(1, 2, 3); !errors.Is(err, ErrOverflow) {
+			t.Errorf("PushMany() beyond new capacity error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		s := New[int](WithCapacity[int](1))
+		if err := s.ResetWithCapacity(UnlimitedCapacity); err != nil {
+			t.Fatalf("ResetWithCapacity(UnlimitedCapacity) error = %v, want nil", err)
+		}
+		if err := s.PushMany(1, 2, 3); err != nil {
+			t.Errorf("PushMany() after ResetWithCapacity(UnlimitedCapacity) error = %v, want nil", err)
+		}
+	})
+
+	t.Run("invalid capacity", func(t *testing.T) {
+		s := New[int](WithCapacity[int](5), WithItems([]int{1}))
+
+		if err := s.ResetWithCapacity(-5); !errors.Is(err, ErrInvalidCapacity) {
+			t.Errorf("ResetWithCapacity(-5) error = %v, want ErrInvalidCapacity", err)
+		}
+		if got := s.Capacity(); got != 5 {
+			t.Errorf("Capacity after rejected ResetWithCapacity() = %d, want 5", got)
+		}
+		if size := s.Size(); size != 1 {
+			t.Errorf("Size after rejected ResetWithCapacity() = %d, want 1", size)
+		}
+	})
+}
+
+func TestSetCapacity(t *testing.T) {
+	t.Run("raise", func(t *testing.T) {
+		s := New[int](WithCapacity[int](2), WithItems([]int{1, 2}))
+
+		dropped, err := s.SetCapacity(4)
+		if err != nil || dropped != 0 {
+			t.Errorf("SetCapacity(4) = %d, %v, want 0, nil", dropped, err)
+		}
+		if err := s.PushMany(3, 4); err != nil {
+			t.Errorf("PushMany() after raising capacity error = %v, want nil", err)
+		}
+	})
+
+	t.Run("lower truncates bottom", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2, 3, 4, 5}))
+
+		dropped, err := s.SetCapacity(2)
+		if err != nil || dropped != 3 {
+			t.Errorf("SetCapacity(2) = %d, %v, want 3, nil", dropped, err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{4, 5}) {
+			t.Errorf("ToSlice() after SetCapacity(2) = %v, want [4 5]", got)
+		}
+		if got := s.Capacity(); got != 2 {
+			t.Errorf("Capacity after SetCapacity(2) = %d, want 2", got)
+		}
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		s := New[int](WithCapacity[int](1), WithItems([]int{1}))
+
+		dropped, err := s.SetCapacity(UnlimitedCapacity)
+		if err != nil || dropped != 0 {
+			t.Errorf("SetCapacity(UnlimitedCapacity) = %d, %v, want 0, nil", dropped, err)
+		}
+		if s.IsFull() {
+			t.Error("IsFull() after SetCapacity(UnlimitedCapacity) = true, want false")
+		}
+	})
+
+	t.Run("invalid capacity", func(t *testing.T) {
+		s := New[int](WithItems([]int{1}))
+
+		if _, err := s.SetCapacity(-2); !errors.Is(err, ErrInvalidCapacity) {
+			t.Errorf("SetCapacity(-2) error = %v, want ErrInvalidCapacity", err)
+		}
+		if got := s.Capacity(); got != UnlimitedCapacity {
+			t.Errorf("Capacity after rejected SetCapacity() = %d, want %d", got, UnlimitedCapacity)
+		}
+	})
+}
+
+func TestGrow(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		s := newStack[int](WithItems([]int{1, 2}))
+		s.Grow(100)
+
+		if c := cap(s.items); c < 102 {
+			t.Errorf("cap(items) after Grow(100) = %d, want >= 102", c)
+		}
+		if !slices.Equal(s.ToSlice(), []int{1, 2}) {
+			t.Errorf("ToSlice() after Grow() = %v, want [1 2]", s.ToSlice())
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		s := newStack[int](WithCapacity[int](10))
+		s.Grow(100)
+
+		if c := cap(s.items); c != 10 {
+			t.Errorf("cap(items) after Grow(100) on capacity 10 = %d, want 10", c)
+		}
+		if size := s.Size(); size != 0 {
+			t.Errorf("Size after Grow() = %d, want 0", size)
+		}
+	})
+
+	t.Run("no allocations after grow", func(t *testing.T) {
+		s := newStack[int]()
+		// AllocsPerRun calls the function twice, including a warm-up run
+		s.Grow(2000)
+
+		allocs := testing.AllocsPerRun(1, func() {
+			for i := 0; i < 1000; i++ {
+				_ = s.Push(i)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("allocations pushing into grown stack = %v, want 0", allocs)
+		}
+	})
+
+	t.Run("negative (should panic)", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Grow(-1) should panic, but it didn't")
+			}
+		}()
+
+		New[int]().Grow(-1)
+	})
+}
+
+func TestShrinkToFit(t *testing.T) {
+	t.Run("after burst", func(t *testing.T) {
+		s := newStack[int]()
+		for i := 0; i < 1000; i++ {
+			_ = s.Push(i)
+		}
+		for i := 0; i < 990; i++ {
+			_, _ = s.Pop()
+		}
+
+		s.ShrinkToFit()
+
+		if c := cap(s.items); c != 10 {
+			t.Errorf("cap(items) after ShrinkToFit() = %d, want 10", c)
+		}
+		if !slices.Equal(s.ToSlice(), []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+			t.Errorf("ToSlice() after ShrinkToFit() = %v, want [0 ... 9]", s.ToSlice())
+		}
+	})
+
+	t.Run("empty stack", func(t *testing.T) {
+		s := newStack[int]()
+		for i := 0; i < 100; i++ {
+			_ = s.Push(i)
+		}
+		s.Clear()
+
+		s.ShrinkToFit()
+
+		if c := cap(s.items); c != 0 {
+			t.Errorf("cap(items) after ShrinkToFit() on empty stack = %d, want 0", c)
+		}
+		if err := s.Push(1); err != nil {
+			t.Errorf("Push() after ShrinkToFit() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestWithThreadSafety(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		s := New[int](WithThreadSafety[int](enabled), WithCapacity[int](3))
+
+		if err := s.PushMany(1, 2, 3); err != nil {
+			t.Errorf("PushMany() with thread safety %v error = %v, want nil", enabled, err)
+		}
+		if err := s.Push(4); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() with thread safety %v error = %v, want ErrOverflow", enabled, err)
+		}
+		if val, _ := s.Pop(); val != 3 {
+			t.Errorf("Pop() with thread safety %v = %d, want 3", enabled, val)
+		}
+
+		c := s.Clone()
+		if got := c.(*stack[int]).unsynced; got != !enabled {
+			t.Errorf("Clone() with thread safety %v unsynced = %v, want %v", enabled, got, !enabled)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	s := New[int](WithCapacity[int](10), WithItems([]int{1, 2, 3, 4, 5, 6}))
+
+	even := s.Filter(func(v int) bool { return v%2 == 0 })
+
+	if got := even.ToSlice(); !slices.Equal(got, []int{2, 4, 6}) {
+		t.Errorf("Filter() = %v, want [2 4 6]", got)
+	}
+	if got := even.Capacity(); got != 10 {
+		t.Errorf("Filter().Capacity() = %d, want 10", got)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("source after Filter() = %v, want [1 2 3 4 5 6]", got)
+	}
+
+	_ = even.Push(8)
+	if size := s.Size(); size != 6 {
+		t.Errorf("source Size after pushing to filtered stack = %d, want 6", size)
+	}
+
+	if none := s.Filter(func(int) bool { return false }); !none.IsEmpty() {
+		t.Errorf("Filter() matching nothing Size = %d, want 0", none.Size())
+	}
+}
+
+func TestDrainAll(t *testing.T) {
+	s := NewOrdered[int](WithItems([]int{1, 2, 3}))
+
+	batch := s.DrainAll()
+	if !slices.Equal(batch, []int{1, 2, 3}) {
+		t.Errorf("DrainAll() = %v, want [1 2 3]", batch)
+	}
+	if !s.IsEmpty() {
+		t.Errorf("Size() after DrainAll() = %d, want 0", s.Size())
+	}
+	if _, err := s.Min(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Min() after DrainAll() error = %v, want ErrUnderflow", err)
+	}
+
+	_ = s.Push(9)
+	if !slices.Equal(batch, []int{1, 2, 3}) {
+		t.Errorf("batch after Push() = %v, want [1 2 3]", batch)
+	}
+
+	_ = s.Close()
+	if got := s.DrainAll(); !slices.Equal(got, []int{9}) {
+		t.Errorf("DrainAll() after Close() = %v, want [9]", got)
+	}
+	if got := New[int]().DrainAll(); len(got) != 0 {
+		t.Errorf("DrainAll() of empty stack = %v, want []", got)
+	}
+}
+
+func TestWithName(t *testing.T) {
+	s := New[int](WithName[int]("jobs"), WithCapacity[int](2), WithItems([]int{1, 2}))
+
+	if got := s.Name(); got != "jobs" {
+		t.Errorf("Name() = %q, want %q", got, "jobs")
+	}
+	err := s.Push(3)
+	var overflow *OverflowError
+	if !errors.As(err, &overflow) || overflow.Name != "jobs" {
+		t.Fatalf("Push() error = %v, want *OverflowError named jobs", err)
+	}
+	if got, want := err.Error(), `stack overflow in "jobs" (size 2, capacity 2)`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	if got := s.Clone().Name(); got != "jobs" {
+		t.Errorf("Clone().Name() = %q, want %q", got, "jobs")
+	}
+	if got := New[int]().Name(); got != "" {
+		t.Errorf("Name() of unnamed stack = %q, want empty", got)
+	}
+
+	sharded := NewSharded[int](2, WithName[int]("shared"))
+	_, err = sharded.Pop()
+	var underflow *UnderflowError
+	if !errors.As(err, &underflow) || underflow.Name != "shared" || sharded.Name() != "shared" {
+		t.Errorf("sharded Pop() error = %v, want *UnderflowError named shared", err)
+	}
+}
+
+func TestRetainFunc(t *testing.T) {
+	s := NewOrdered[int](WithItems([]int{1, 2, 3, 4, 5, 6}))
+	even := func(v int) bool { return v%2 == 0 }
+
+	if removed := s.RetainFunc(even); removed != 3 {
+		t.Errorf("RetainFunc() = %d, want 3", removed)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{2, 4, 6}) {
+		t.Errorf("ToSlice() after RetainFunc() = %v, want [2 4 6]", got)
+	}
+	if lo, _ := s.Min(); lo != 2 {
+		t.Errorf("Min() after RetainFunc() = %d, want 2", lo)
+	}
+	if removed := s.RetainFunc(even); removed != 0 {
+		t.Errorf("second RetainFunc() = %d, want 0", removed)
+	}
+	if removed := s.RetainFunc(func(int) bool { return false }); removed != 3 || !s.IsEmpty() {
+		t.Errorf("RetainFunc(none) = %d, size %d, want 3, 0", removed, s.Size())
+	}
+}
+
+func TestCompactFunc(t *testing.T) {
+	s := New[string](WithItems([]string{"a", "A", "b", "b", "B", "a"}))
+
+	removed := s.CompactFunc(strings.EqualFold)
+	if removed != 3 {
+		t.Errorf("CompactFunc() = %d, want 3", removed)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []string{"a", "b", "a"}) {
+		t.Errorf("ToSlice() after CompactFunc() = %v, want [a b a]", got)
+	}
+	if removed := s.CompactFunc(strings.EqualFold); removed != 0 {
+		t.Errorf("second CompactFunc() = %d, want 0", removed)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Stack[int]
+		want string
+	}{
+		{name: "empty unlimited", s: New[int](), want: "Stack[0/∞]: []"},
+		{name: "bounded", s: New[int](WithCapacity[int](10), WithItems([]int{1, 2, 3})), want: "Stack[3/10]: [1 2 3]"},
+		{name: "sharded", s: NewSharded[int](2, WithCapacity[int](4), WithItems([]int{1, 2})), want: "Stack[2/4]: [1 2]"},
+		{name: "named", s: New[int](WithName[int]("jobs"), WithItems([]int{1})), want: `Stack "jobs"[1/∞]: [1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(tt.s); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReverse(t *testing.T) {
+	s := New[int](WithCapacity[int](5), WithItems([]int{1, 2, 3, 4}))
+	s.Reverse()
+
+	if got := s.ToSlice(); !slices.Equal(got, []int{4, 3, 2, 1}) {
+		t.Errorf("ToSlice() after Reverse() = %v, want [4 3 2 1]", got)
+	}
+	if got := s.Capacity(); got != 5 {
+		t.Errorf("Capacity after Reverse() = %d, want 5", got)
+	}
+	if val, _ := s.Pop(); val != 1 {
+		t.Errorf("Pop() after Reverse() = %d, want 1", val)
+	}
+
+	empty := New[int]()
+	empty.Reverse()
+	if !empty.IsEmpty() {
+		t.Errorf("Size after Reverse() on empty stack = %d, want 0", empty.Size())
+	}
+
+	ordered := NewOrdered[int](WithItems([]int{1, 5, 3}))
+	ordered.Reverse()
+	_, _ = ordered.Pop()
+	if hi, _ := ordered.Max(); hi != 5 {
+		t.Errorf("Max() after Reverse() and Pop() = %d, want 5", hi)
+	}
+	if lo, _ := ordered.Min(); lo != 3 {
+		t.Errorf("Min() after Reverse() and Pop() = %d, want 3", lo)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3}))
+
+	if err := s.Swap(); err != nil {
+		t.Fatalf("Swap() error = %v, want nil", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 3, 2}) {
+		t.Errorf("ToSlice() after Swap() = %v, want [1 3 2]", got)
+	}
+
+	one := New[int](WithItems([]int{1}))
+	if err := one.Swap(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Swap() with one item error = %v, want ErrUnderflow", err)
+	}
+	if got := one.ToSlice(); !slices.Equal(got, []int{1}) {
+		t.Errorf("ToSlice() after failed Swap() = %v, want [1]", got)
+	}
+
+	ordered := NewOrdered[int](WithItems([]int{5, 1, 9}))
+	_ = ordered.Swap()
+	_, _ = ordered.Pop()
+	if hi, _ := ordered.Max(); hi != 9 {
+		t.Errorf("Max() after Swap() and Pop() = %d, want 9", hi)
+	}
+}
+
+func TestDup(t *testing.T) {
+	s := New[int](WithCapacity[int](3), WithItems([]int{1, 2}))
+
+	if err := s.Dup(); err != nil {
+		t.Fatalf("Dup() error = %v, want nil", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 2}) {
+		t.Errorf("ToSlice() after Dup() = %v, want [1 2 2]", got)
+	}
+
+	if err := s.Dup(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Dup() on full stack error = %v, want ErrOverflow", err)
+	}
+	if size := s.Size(); size != 3 {
+		t.Errorf("Size after failed Dup() = %d, want 3", size)
+	}
+
+	if err := New[int]().Dup(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("Dup() on empty stack error = %v, want ErrUnderflow", err)
+	}
+}
+
+func TestSplitAt(t *testing.T) {
+	s := New[int](WithCapacity[int](5), WithItems([]int{1, 2, 3, 4}))
+
+	bottom, top, err := s.SplitAt(1)
+	if err != nil {
+		t.Fatalf("SplitAt(1) error = %v, want nil", err)
+	}
+	if bottom != s {
+		t.Error("SplitAt() bottom is not the receiver")
+	}
+	if got := bottom.ToSlice(); !slices.Equal(got, []int{1}) {
+		t.Errorf("bottom after SplitAt(1) = %v, want [1]", got)
+	}
+	if got := top.ToSlice(); !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("top after SplitAt(1) = %v, want [2 3 4]", got)
+	}
+	if top.Capacity() != 5 {
+		t.Errorf("top Capacity() = %d, want 5", top.Capacity())
+	}
+
+	if _, _, err := s.SplitAt(2); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("SplitAt(2) on stack of 1 error = %v, want ErrUnderflow", err)
+	}
+
+	_, top, _ = s.SplitAt(1)
+	if !top.IsEmpty() || s.Size() != 1 {
+		t.Errorf("SplitAt(Size()) = %v and %v, want everything in bottom", s, top)
+	}
+
+	if _, _, err := s.SplitAt(-1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("SplitAt(-1) error = %v, want ErrIndexOutOfRange", err)
+	}
+}
+
+func TestPushBottom(t *testing.T) {
+	s := NewOrdered[int](WithCapacity[int](3), WithItems([]int{2, 3}))
+
+	if err := s.PushBottom(1); err != nil {
+		t.Fatalf("PushBottom() error = %v, want nil", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("ToSlice() after PushBottom() = %v, want [1 2 3]", got)
+	}
+	if lo, _ := s.Min(); lo != 1 {
+		t.Errorf("Min() after PushBottom() = %d, want 1", lo)
+	}
+
+	if err := s.PushBottom(0); !errors.Is(err, ErrOverflow) {
+		t.Errorf("PushBottom() on full stack error = %v, want ErrOverflow", err)
+	}
+
+	dropOldest := New[int](WithCapacity[int](1), WithOverflowPolicy[int](OverflowDropOldest), WithItems([]int{1}))
+	if err := dropOldest.PushBottom(0); !errors.Is(err, ErrOverflow) {
+		t.Errorf("PushBottom() on full drop-oldest stack error = %v, want ErrOverflow", err)
+	}
+}
+
+func TestPopBottom(t *testing.T) {
+	s := NewOrdered[int](WithItems([]int{1, 2, 3}))
+
+	val, err := s.PopBottom()
+	if err != nil || val != 1 {
+		t.Fatalf("PopBottom() = %d, %v, want 1, nil", val, err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{2, 3}) {
+		t.Errorf("ToSlice() after PopBottom() = %v, want [2 3]", got)
+	}
+	if lo, _ := s.Min(); lo != 2 {
+		t.Errorf("Min() after PopBottom() = %d, want 2", lo)
+	}
+
+	_, _ = s.PopBottom()
+	_, _ = s.PopBottom()
+	if _, err := s.PopBottom(); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("PopBottom() on empty stack error = %v, want ErrUnderflow", err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want []int
+	}{
+		{"one", 1, []int{4, 1, 2, 3}},
+		{"three", 3, []int{2, 3, 4, 1}},
+		{"negative", -1, []int{2, 3, 4, 1}},
+		{"wraps", 5, []int{4, 1, 2, 3}},
+		{"multiple of size", 8, []int{1, 2, 3, 4}},
+		{"zero", 0, []int{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewOrdered[int](WithItems([]int{1, 2, 3, 4}))
+			if err := s.Rotate(tt.n); err != nil {
+				t.Fatalf("Rotate(%d) error = %v", tt.n, err)
+			}
+			if got := s.ToSlice(); !slices.Equal(got, tt.want) {
+				t.Errorf("ToSlice() after Rotate(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+			if top, _ := s.Peek(); top != tt.want[3] {
+				t.Errorf("Peek() after Rotate(%d) = %d, want %d", tt.n, top, tt.want[3])
+			}
+			if hi, _ := s.Max(); hi != 4 {
+				t.Errorf("Max() after Rotate(%d) = %d, want 4", tt.n, hi)
+			}
+		})
+	}
+
+	empty := New[int]()
+	if err := empty.Rotate(3); err != nil || !empty.IsEmpty() {
+		t.Errorf("Rotate() on empty stack = %v, want no-op", err)
+	}
+}
+
+func TestPeekAt(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3}))
+
+	for depth, want := range []int{3, 2, 1} {
+		if val, err := s.PeekAt(depth); err != nil || val != want {
+			t.Errorf("PeekAt(%d) = %d, %v, want %d, nil", depth, val, err, want)
+		}
+	}
+	for _, depth := range []int{-1, 3} {
+		if _, err := s.PeekAt(depth); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("PeekAt(%d) error = %v, want ErrIndexOutOfRange", depth, err)
+		}
+	}
+	if size := s.Size(); size != 3 {
+		t.Errorf("Size() after PeekAt() = %d, want 3", size)
+	}
+}
+
+func TestSwapAt(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3, 4}))
+
+	if err := s.SwapAt(0, 3); err != nil {
+		t.Fatalf("SwapAt(0, 3) error = %v, want nil", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{4, 2, 3, 1}) {
+		t.Errorf("ToSlice() after SwapAt(0, 3) = %v, want [4 2 3 1]", got)
+	}
+	if err := s.SwapAt(2, 2); err != nil {
+		t.Errorf("SwapAt(2, 2) error = %v, want nil", err)
+	}
+
+	for _, depths := range [][2]int{{0, 4}, {-1, 0}} {
+		if err := s.SwapAt(depths[0], depths[1]); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("SwapAt(%d, %d) error = %v, want ErrIndexOutOfRange", depths[0], depths[1], err)
+		}
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{4, 2, 3, 1}) {
+		t.Errorf("ToSlice() after failed SwapAt() = %v, want [4 2 3 1]", got)
+	}
+
+	ordered := NewOrdered[int](WithItems([]int{9, 1, 5}))
+	_ = ordered.SwapAt(0, 2)
+	_, _ = ordered.Pop()
+	if hi, _ := ordered.Max(); hi != 5 {
+		t.Errorf("Max() after SwapAt() and Pop() = %d, want 5", hi)
+	}
+}
+
+func TestCopyInto(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3}))
+
+	buf := make([]int, 5)
+	if n := s.CopyInto(buf); n != 3 || !slices.Equal(buf[:n], []int{1, 2, 3}) {
+		t.Errorf("CopyInto() = %d, %v, want 3, [1 2 3]", n, buf[:n])
+	}
+
+	short := make([]int, 2)
+	if n := s.CopyInto(short); n != 2 || !slices.Equal(short, []int{1, 2}) {
+		t.Errorf("CopyInto() into short buffer = %d, %v, want 2, [1 2]", n, short)
+	}
+
+	if n := s.CopyInto(nil); n != 0 {
+		t.Errorf("CopyInto(nil) = %d, want 0", n)
+	}
+}
+
+func TestAppendTo(t *testing.T) {
+	s := New[int](WithItems([]int{1, 2, 3}))
+
+	buf := make([]int, 1, 8)
+	got := s.AppendTo(buf)
+	if !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("AppendTo() = %v, want [0 1 2 3]", got)
+	}
+	if &got[0] != &buf[0] {
+		t.Error("AppendTo() reallocated a slice with enough capacity")
+	}
+
+	if got := New[int]().AppendTo(nil); got != nil {
+		t.Errorf("AppendTo(nil) on empty stack = %v, want nil", got)
+	}
+}
+
+func TestWithFixedArray(t *testing.T) {
+	s := New[int](WithCapacity[int](100), WithFixedArray[int](), WithItems([]int{1, 2}))
+	if got := cap(s.(*stack[int]).items); got != 100 {
+		t.Errorf("storage after New() = %d, want 100", got)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := 0; i < 98; i++ {
+			_ = s.Push(i)
+		}
+		for i := 0; i < 98; i++ {
+			_, _ = s.Pop()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Push() and Pop() on fixed stack allocated %v times, want 0", allocs)
+	}
+
+	s.ShrinkToFit()
+	if got := cap(s.(*stack[int]).items); got != 100 {
+		t.Errorf("storage after ShrinkToFit() = %d, want 100", got)
+	}
+	if _, err := s.SetCapacity(10); err != nil {
+		t.Fatalf("SetCapacity(10) error = %v", err)
+	}
+	if got := cap(s.(*stack[int]).items); got != 10 {
+		t.Errorf("storage after SetCapacity(10) = %d, want 10", got)
+	}
+
+	if clone := s.Clone().(*stack[int]); cap(clone.items) != 10 {
+		t.Errorf("storage of clone = %d, want 10", cap(clone.items))
+	}
+
+	small := New[int](WithCapacity[int](8))
+	if got := cap(small.(*stack[int]).items); got != 0 {
+		t.Errorf("storage of small stack without WithFixedArray = %d, want 0", got)
+	}
+	_ = small.Push(1)
+	_, _ = small.Pop()
+	small.ShrinkToFit()
+	if got := cap(small.(*stack[int]).items); got != 0 {
+		t.Errorf("storage of empty stack after ShrinkToFit() = %d, want 0", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("New() with WithFixedArray and unlimited capacity did not panic")
+		}
+	}()
+	New[int](WithFixedArray[int]())
+}
+
+func TestNewChecked(t *testing.T) {
+	s, err := NewChecked[int](WithCapacity[int](2), WithItems([]int{1}))
+	if err != nil {
+		t.Fatalf("NewChecked() error = %v, want nil", err)
+	}
+	if s.Capacity() != 2 || s.Size() != 1 {
+		t.Errorf("NewChecked() = capacity %d, size %d, want 2, 1", s.Capacity(), s.Size())
+	}
+
+	sizeOf := func(b []byte) int { return len(b) }
+	tests := []struct {
+		name string
+		new  func() error
+		want error
+	}{
+		{"negative capacity", func() error {
+			_, err := NewChecked[int](WithCapacity[int](-5))
+			return err
+		}, ErrInvalidCapacity},
+		{"negative initial capacity", func() error {
+			_, err := NewChecked[int](WithInitialCapacity[int](-1))
+			return err
+		}, ErrInvalidCapacity},
+		{"unlimited fixed array", func() error {
+			_, err := NewChecked[int](WithFixedArray[int]())
+			return err
+		}, ErrInvalidCapacity},
+		{"negative byte limit", func() error {
+			_, err := NewChecked[[]byte](WithMaxBytes(-1, sizeOf))
+			return err
+		}, ErrInvalidOption},
+		{"nil size function", func() error {
+			_, err := NewChecked[[]byte](WithMaxBytes[[]byte](10, nil))
+			return err
+		}, ErrInvalidOption},
+		{"non-positive report interval", func() error {
+			_, err := NewChecked[int](WithMetricsReporter[int](0, func(Stats) {}))
+			return err
+		}, ErrInvalidOption},
+		{"half a binary codec", func() error {
+			_, err := NewChecked[int](WithBinaryCodec[int](nil, func([]byte) (int, error) { return 0, nil }))
+			return err
+		}, ErrInvalidOption},
+		{"duplicate items", func() error {
+			_, err := NewChecked[int](WithUniqueness[int](true), WithItems([]int{1, 2, 1}))
+			return err
+		}, ErrDuplicate},
+		{"too many items", func() error {
+			_, err := NewChecked[int](WithCapacity[int](1), WithItems([]int{1, 2}))
+			return err
+		}, ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.new(); !errors.Is(err, tt.want) {
+				t.Errorf("NewChecked() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateTop(t *testing.T) {
+	type counter struct{ n int }
+	s := New[counter](WithItems([]counter{{1}, {2}}))
+
+	if err := s.UpdateTop(func(c *counter) error {
+		c.n += 10
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateTop() error = %v", err)
+	}
+	if top, _ := s.Peek(); top.n != 12 {
+		t.Errorf("Peek() after UpdateTop() = %d, want 12", top.n)
+	}
+
+	errStop := errors.New("stop")
+	if err := s.UpdateTop(func(c *counter) error {
+		c.n = 0
+		return errStop
+	}); !errors.Is(err, errStop) {
+		t.Errorf("UpdateTop() error = %v, want %v", err, errStop)
+	}
+	if top, _ := s.Peek(); top.n != 12 {
+		t.Errorf("Peek() after failed UpdateTop() = %d, want 12", top.n)
+	}
+
+	ordered := NewOrdered[int](WithItems([]int{5, 3}))
+	_ = ordered.UpdateTop(func(v *int) error {
+		*v = 9
+		return nil
+	})
+	if lo, _ := ordered.Min(); lo != 5 {
+		t.Errorf("Min() after UpdateTop() = %d, want 5", lo)
+	}
+	if hi, _ := ordered.Max(); hi != 9 {
+		t.Errorf("Max() after UpdateTop() = %d, want 9", hi)
+	}
+
+	bounded := New[string](WithMaxBytes(4, func(s string) int { return len(s) }), WithItems([]string{"ab"}))
+	if err := bounded.UpdateTop(func(s *string) error {
+		*s = "abcde"
+		return nil
+	}); !errors.Is(err, ErrOverflow) {
+		t.Errorf("UpdateTop() over byte limit error = %v, want ErrOverflow", err)
+	}
+	if top, _ := bounded.Peek(); top != "ab" {
+		t.Errorf("Peek() after rejected UpdateTop() = %q, want %q", top, "ab")
+	}
+
+	if err := New[int]().UpdateTop(func(*int) error { return nil }); !errors.Is(err, ErrUnderflow) {
+		t.Errorf("UpdateTop() on empty stack error = %v, want ErrUnderflow", err)
+	}
+}
+
+func TestWithGrowthPolicy(t *testing.T) {
+	var calls []int
+	s := New[int](WithGrowthPolicy[int](func(n int) int {
+		calls = append(calls, n)
+		return n + 10
+	}))
+
+	for i := 0; i < 25; i++ {
+		_ = s.Push(i)
+	}
+	if !slices.Equal(calls, []int{0, 10, 20}) {
+		t.Errorf("growth policy called with %v, want [0 10 20]", calls)
+	}
+	if got := cap(s.(*stack[int]).items); got != 30 {
+		t.Errorf("storage after 25 pushes = %d, want 30", got)
+	}
+
+	stingy := New[int](WithGrowthPolicy[int](func(int) int { return 0 }))
+	_ = stingy.PushMany(1, 2, 3)
+	if got := stingy.ToSlice(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("ToSlice() with non-growing policy = %v, want [1 2 3]", got)
+	}
+}
+
+func TestPopUntil(t *testing.T) {
+	s := New[string](WithItems([]string{"1", "(", "+", "*"}))
+	isOperator := func(v string) bool { return v == "+" || v == "*" }
+
+	popped, err := s.PopUntil(isOperator)
+	if err != nil || !slices.Equal(popped, []string{"*", "+"}) {
+		t.Fatalf("PopUntil() = %v, %v, want [* +], nil", popped, err)
+	}
+	if top, _ := s.Peek(); top != "(" {
+		t.Errorf("Peek() after PopUntil() = %q, want %q", top, "(")
+	}
+
+	if popped, err := s.PopUntil(isOperator); err != nil || len(popped) != 0 {
+		t.Errorf("PopUntil() with rejected top = %v, %v, want [], nil", popped, err)
+	}
+	if popped, _ := s.PopUntil(func(string) bool { return true }); len(popped) != 2 || !s.IsEmpty() {
+		t.Errorf("PopUntil(always) = %v, size %d, want 2 items, 0", popped, s.Size())
+	}
+	if stats := s.Stats(); stats.Pops != 4 {
+		t.Errorf("Stats().Pops = %d, want 4", stats.Pops)
+	}
+}
+
+func TestFind(t *testing.T) {
+	s := New[int](WithItems([]int{2, 5, 4, 7, 1}))
+
+	if val, ok := s.Find(func(v int) bool { return v%2 == 0 }); !ok || val != 4 {
+		t.Errorf("Find(even) = %d, %v, want 4, true", val, ok)
+	}
+	if val, ok := s.Find(func(v int) bool { return v > 10 }); ok || val != 0 {
+		t.Errorf("Find(> 10) = %d, %v, want 0, false", val, ok)
+	}
+	if size := s.Size(); size != 5 {
+		t.Errorf("Size() after Find() = %d, want 5", size)
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Stack[int]
+		want float64
+	}{
+		{"empty", New[int](WithCapacity[int](4)), 0},
+		{"half full", New[int](WithCapacity[int](4), WithItems([]int{1, 2})), 0.5},
+		{"full", New[int](WithCapacity[int](2), WithItems([]int{1, 2})), 1},
+		{"zero capacity", New[int](WithCapacity[int](0)), 1},
+		{"unlimited", New[int](WithItems([]int{1})), -1},
+		{"sharded", NewSharded[int](2, WithCapacity[int](8), WithItems([]int{1, 2})), 0.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Utilization(); got != tt.want {
+				t.Errorf("Utilization() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverflowPolicy(t *testing.T) {
+	newDropOldest := func(capacity int, items ...int) Stack[int] {
+		return New[int](
+			WithCapacity[int](capacity),
+			WithOverflowPolicy[int](OverflowDropOldest),
+			WithItems(items),
+		)
+	}
+
+	t.Run("reject is default", func(t *testing.T) {
+		s := New[int](WithCapacity[int](1), WithItems([]int{1}))
+		if err := s.Push(2); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() on full stack error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("drop oldest on push", func(t *testing.T) {
+		s := newDropOldest(3, 1, 2, 3)
+
+		if err := s.Push(4); err != nil {
+			t.Errorf("Push() on full stack error = %v, want nil", err)
+		}
+		if !s.TryPush(5) {
+			t.Error("TryPush() on full stack = false, want true")
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{3, 4, 5}) {
+			t.Errorf("ToSlice() = %v, want [3 4 5]", got)
+		}
+	})
+
+	t.Run("drop oldest on push many", func(t *testing.T) {
+		s := newDropOldest(3, 1, 2)
+
+		if err := s.PushMany(3, 4); err != nil {
+			t.Errorf("PushMany() error = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{2, 3, 4}) {
+			t.Errorf("ToSlice() after PushMany(3, 4) = %v, want [2 3 4]", got)
+		}
+
+		if err := s.PushMany(5, 6, 7, 8, 9); err != nil {
+			t.Errorf("PushMany() error = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{7, 8, 9}) {
+			t.Errorf("ToSlice() after PushMany(5, ..., 9) = %v, want [7 8 9]", got)
+		}
+	})
+
+	t.Run("drop oldest on dup", func(t *testing.T) {
+		s := newDropOldest(2, 1, 2)
+
+		if err := s.Dup(); err != nil {
+			t.Errorf("Dup() on full stack error = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{2, 2}) {
+			t.Errorf("ToSlice() after Dup() = %v, want [2 2]", got)
+		}
+	})
+
+	t.Run("drop oldest with capacity one", func(t *testing.T) {
+		s := newDropOldest(1, 1)
+
+		if err := s.Dup(); err != nil {
+			t.Errorf("Dup() error = %v, want nil", err)
+		}
+		if err := s.Push(2); err != nil {
+			t.Errorf("Push() error = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{2}) {
+			t.Errorf("ToSlice() = %v, want [2]", got)
+		}
+	})
+
+	t.Run("drop oldest never blocks", func(t *testing.T) {
+		s := newDropOldest(1, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := s.BlockingPush(ctx, 2); err != nil {
+			t.Errorf("BlockingPush() on full stack error = %v, want nil", err)
+		}
+	})
+
+	t.Run("zero capacity", func(t *testing.T) {
+		s := newDropOldest(0)
+
+		if err := s.Push(1); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Push() on zero capacity stack error = %v, want ErrOverflow", err)
+		}
+		if err := s.PushMany(1); !errors.Is(err, ErrOverflow) {
+			t.Errorf("PushMany() on zero capacity stack error = %v, want ErrOverflow", err)
+		}
+	})
+
+	t.Run("ordered bookkeeping", func(t *testing.T) {
+		s := NewOrdered[int](
+			WithCapacity[int](2),
+			WithOverflowPolicy[int](OverflowDropOldest),
+			WithItems([]int{1, 9}),
+		)
+
+		_ = s.Push(5)
+		if lo, _ := s.Min(); lo != 5 {
+			t.Errorf("Min() after eviction = %d, want 5", lo)
+		}
+	})
+}
+
+// Benchmark tests
+func BenchmarkPush(b *testing.B) {
+	s := New[int]()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = s.Push(i)
+	}
+}
+
+func BenchmarkPushUnsynchronized(b *testing.B) {
+	s := New[int](WithThreadSafety[int](false))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = s.Push(i)
+	}
+}
+
+func BenchmarkPushFixed(b *testing.B) {
+	const capacity = 1024
+	s := New[int](WithCapacity[int](capacity), WithFixedArray[int]())
+	for i := 0; i < capacity-1; i++ {
+		_ = s.Push(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	// Each push fills the stack to capacity; the pop makes room for the next.
+	for i := 0; i < b.N; i++ {
+		_ = s.Push(i)
+		_, _ = s.Pop()
 	}
 }
 
@@ -0,0 +1,159 @@
+package stack
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds cumulative operational counters for a stack.
+type Stats struct {
+	// Pushes is the number of items successfully pushed.
+	// Bulk operations such as PushMany count each item.
+	Pushes uint64
+
+	// Pops is the number of items successfully popped.
+	Pops uint64
+
+	// Overflows is the number of operations rejected because the stack was full.
+	Overflows uint64
+
+	// Underflows is the number of operations rejected because the stack was empty
+	// or held too few items.
+	Underflows uint64
+
+	// MaxSize is the largest number of items the stack has held at once.
+	MaxSize int
+}
+
+// counters maintains the values reported by Stats. All fields are updated
+// atomically so they can be read without holding the stack's lock.
+type counters struct {
+	pushes     atomic.Uint64
+	pops       atomic.Uint64
+	overflows  atomic.Uint64
+	underflows atomic.Uint64
+	maxSize    atomic.Int64
+}
+
+// updateMaxSize raises the recorded maximum size to n if it is larger.
+func (c *counters) updateMaxSize(n int) {
+	for {
+		cur := c.maxSize.Load()
+		if int64(n) <= cur || c.maxSize.CompareAndSwap(cur, int64(n)) {
+			return
+		}
+	}
+}
+
+// snapshot returns the current values of the counters.
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Pushes:     c.pushes.Load(),
+		Pops:       c.pops.Load(),
+		Overflows:  c.overflows.Load(),
+		Underflows: c.underflows.Load(),
+		MaxSize:    int(c.maxSize.Load()),
+	}
+}
+
+func (s *stack[T]) Stats() Stats {
+	return s.stats.snapshot()
+}
+
+func (s *sharded[T]) Stats() Stats {
+	result := s.stats.snapshot()
+	for _, sh := range s.shards {
+		st := sh.stats.snapshot()
+		result.Pushes += st.Pushes
+		result.Pops += st.Pops
+		result.MaxSize += st.MaxSize
+	}
+
+	return result
+}
+
+// trackDepth records the current size in the high-water marks.
+// Callers must hold the write lock.
+func (s *stack[T]) trackDepth() {
+	s.noteDepth(len(s.items))
+}
+
+// noteDepth records a size of n items in the high-water marks.
+// Callers must hold the write lock.
+func (s *stack[T]) noteDepth(n int) {
+	// MaxDepth never exceeds the all-time maximum in Stats, so a size that
+	// does not raise it cannot raise the maximum either.
+	if n <= s.maxDepth {
+		return
+	}
+
+	s.maxDepth = n
+	s.stats.updateMaxSize(n)
+}
+
+func (s *stack[T]) MaxDepth() int {
+	s.rlock()
+	defer s.runlock()
+
+	return s.maxDepth
+}
+
+func (s *stack[T]) ResetMaxDepth() {
+	s.lock()
+	defer s.unlock()
+
+	s.maxDepth = len(s.items)
+}
+
+func (s *sharded[T]) MaxDepth() int {
+	s.rlockAll()
+	defer s.runlockAll()
+
+	depth := 0
+	for _, sh := range s.shards {
+		depth += sh.maxDepth
+	}
+
+	return depth
+}
+
+func (s *sharded[T]) ResetMaxDepth() {
+	s.lockAll()
+	defer s.unlockAll()
+
+	for _, sh := range s.shards {
+		sh.maxDepth = len(sh.items)
+	}
+}
+
+// startReporting starts the goroutine configured by WithMetricsReporter, if any.
+func (s *stack[T]) startReporting() {
+	if s.report != nil {
+		s.done = make(chan struct{})
+		go reportStats(s.reportEvery, s.report, s.Stats, s.done)
+	}
+}
+
+// startReporting starts the goroutine configured by WithMetricsReporter, if any.
+func (s *sharded[T]) startReporting() {
+	if s.report != nil {
+		s.done = make(chan struct{})
+		go reportStats(s.reportEvery, s.report, s.Stats, s.done)
+	}
+}
+
+// reportStats calls report with the result of stats every interval until done
+// is closed.
+func reportStats(interval time.Duration, report func(Stats), stats func() Stats, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			report(stats())
+		case <-done:
+			return
+		}
+	}
+}
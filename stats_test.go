@@ -0,0 +1,176 @@
+package stack
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	s := New(WithCapacity[int](3))
+
+	if got := s.Stats(); got != (Stats{}) {
+		t.Fatalf("expected zero stats for new stack, got %+v", got)
+	}
+
+	_ = s.PushMany(1, 2, 3)
+	_ = s.Push(4)        // overflow
+	_, _ = s.Pop()       // 3
+	_, _ = s.Pop()       // 2
+	_ = s.Push(5)        // 1 5
+	_ = s.PushMany(6, 7) // overflow
+	_, _ = s.PeekN(5)    // underflow
+	s.Clear()
+	_, _ = s.Pop()  // underflow
+	_, _ = s.Peek() // underflow
+
+	want := Stats{Pushes: 4, Pops: 2, Overflows: 2, Underflows: 3, MaxSize: 3}
+	if got := s.Stats(); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestStatsTryAndDrain(t *testing.T) {
+	s := New(WithCapacity[int](1))
+
+	s.TryPush(1)
+	s.TryPush(2) // overflow
+	for range s.Drain() {
+	}
+	s.TryPop() // underflow
+
+	want := Stats{Pushes: 1, Pops: 1, Overflows: 1, Underflows: 1, MaxSize: 1}
+	if got := s.Stats(); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestStatsSeeded(t *testing.T) {
+	s := New(WithItems([]int{1, 2, 3}))
+
+	if got := s.Stats(); got.MaxSize != 3 || got.Pushes != 0 {
+		t.Errorf("expected MaxSize 3 and no pushes for seeded stack, got %+v", got)
+	}
+}
+
+func TestStatsSharded(t *testing.T) {
+	s := NewSharded(4, WithCapacity[int](8))
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				_ = s.Push(i*100 + j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for range s.Drain() {
+	}
+	_, _ = s.Pop() // underflow
+
+	got := s.Stats()
+	if got.Pushes != 8 || got.Pops != 8 || got.Overflows != 392 || got.Underflows != 1 {
+		t.Errorf("expected 8 pushes and pops, 392 overflows and 1 underflow, got %+v", got)
+	}
+	if got.MaxSize != 8 {
+		t.Errorf("expected MaxSize 8, got %d", got.MaxSize)
+	}
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	s := New[int]()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				_ = s.Push(j)
+				_ = s.Stats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := s.Stats(); got.Pushes != 800 || got.MaxSize != 800 {
+		t.Errorf("expected 800 pushes and MaxSize 800, got %+v", got)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	s := New[int]()
+
+	_ = s.PushMany(1, 2, 3)
+	_, _ = s.Pop()
+	_ = s.Push(4)
+	s.Clear()
+
+	if got := s.MaxDepth(); got != 3 {
+		t.Errorf("expected MaxDepth 3 after Clear, got %d", got)
+	}
+
+	_ = s.Push(5)
+	s.ResetMaxDepth()
+	if got := s.MaxDepth(); got != 1 {
+		t.Errorf("expected MaxDepth 1 after reset, got %d", got)
+	}
+	if got := s.Stats().MaxSize; got != 3 {
+		t.Errorf("expected ResetMaxDepth to leave Stats().MaxSize at 3, got %d", got)
+	}
+
+	_ = s.PushMany(6, 7)
+	if got := s.MaxDepth(); got != 3 {
+		t.Errorf("expected MaxDepth 3 after pushing again, got %d", got)
+	}
+}
+
+func TestMaxDepthSharded(t *testing.T) {
+	s := NewSharded(2, WithCapacity[int](4))
+
+	_ = s.PushMany(1, 2, 3, 4)
+	_, _ = s.Pop()
+	if got := s.MaxDepth(); got != 4 {
+		t.Errorf("expected MaxDepth 4, got %d", got)
+	}
+
+	s.ResetMaxDepth()
+	if got := s.MaxDepth(); got != 3 {
+		t.Errorf("expected MaxDepth 3 after reset, got %d", got)
+	}
+}
+
+func TestWithMetricsReporter(t *testing.T) {
+	for name, newStack := range stackKinds {
+		t.Run(name, func(t *testing.T) {
+			reports := make(chan Stats, 100)
+			s := newStack(WithMetricsReporter[int](time.Millisecond, func(st Stats) {
+				reports <- st
+			}))
+			_ = s.PushMany(1, 2)
+
+			timeout := time.After(time.Second)
+			for st := (Stats{}); st.Pushes != 2; {
+				select {
+				case st = <-reports:
+				case <-timeout:
+					t.Fatalf("last reported Pushes = %d, want 2", st.Pushes)
+				}
+			}
+
+			_ = s.Close()
+			time.Sleep(10 * time.Millisecond)
+			for len(reports) > 0 {
+				<-reports
+			}
+			time.Sleep(10 * time.Millisecond)
+			if n := len(reports); n != 0 {
+				t.Errorf("%d report(s) after Close(), want 0", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package stack
+
+// TxStack is the view of a stack available inside a transaction started with
+// Transaction. Its operations act on a staged copy of the stack that includes
+// the transaction's own uncommitted changes. The changes are applied to the
+// stack only if the transaction commits.
+//
+// A TxStack must not be used after the function passed to Transaction returns.
+type TxStack[T any] interface {
+	// Push stages val on top of the stack.
+	// Returns an *OverflowError wrapping ErrOverflow if the staged stack is at
+	// capacity, unless the overflow policy evicts the oldest item to make room.
+	Push(val T) error
+
+	// Pop removes and returns the top item of the staged stack.
+	// Returns an *UnderflowError wrapping ErrUnderflow if it is empty.
+	Pop() (T, error)
+
+	// Peek returns the top item of the staged stack without removing it.
+	// Returns an *UnderflowError wrapping ErrUnderflow if it is empty.
+	Peek() (T, error)
+
+	// Size returns the number of items on the staged stack.
+	Size() int
+}
+
+// tx stages the changes of a transaction on top of the items of a locked stack.
+// The staged stack is base[lo:hi] followed by pushed: popping consumes pushed
+// first and then lowers hi, while evictions raise lo and then drop from the
+// front of pushed. base itself is never modified.
+type tx[T any] struct {
+	base   []T
+	lo, hi int
+	pushed []T
+
+	// name of the stack, for errors.
+	name string
+
+	// Limits of the stack, as used by stack.within.
+	capacity int
+	policy   OverflowPolicy
+	maxBytes int
+	sizeOf   func(T) int
+	bytes    int
+
+	// events records successful pushes and pops, in order, and evicted the
+	// items dropped by the overflow policy, so they can be reported on commit.
+	// peak is the largest staged size.
+	events  []txEvent[T]
+	evicted []T
+	peak    int
+
+	done bool
+}
+
+// txEvent is a push or pop made during a transaction.
+type txEvent[T any] struct {
+	val  T
+	push bool
+}
+
+// newTx returns a transaction staged on base, limited as s is, except that
+// capacity overrides the capacity of s.
+func newTx[T any](s *stack[T], base []T, capacity int) *tx[T] {
+	return &tx[T]{
+		name:     s.name,
+		base:     base,
+		hi:       len(base),
+		capacity: capacity,
+		policy:   s.policy,
+		maxBytes: s.maxBytes,
+		sizeOf:   s.sizeOf,
+		bytes:    s.bytes,
+		peak:     len(base),
+	}
+}
+
+// check panics if the transaction has ended.
+func (t *tx[T]) check() {
+	if t.done {
+		panic("cannot use a transaction after it has ended")
+	}
+}
+
+// size returns the number of staged items.
+func (t *tx[T]) size() int {
+	return t.hi - t.lo + len(t.pushed)
+}
+
+// sizeOfVal returns the size of val, or zero if there is no byte limit.
+func (t *tx[T]) sizeOfVal(val T) int {
+	if t.sizeOf == nil {
+		return 0
+	}
+
+	return t.sizeOf(val)
+}
+
+// within reports whether n staged items totalling bytes respect the limits.
+func (t *tx[T]) within(n, bytes int) bool {
+	return (t.capacity < 0 || n <= t.capacity) && (t.sizeOf == nil || bytes <= t.maxBytes)
+}
+
+func (t *tx[T]) Push(val T) error {
+	t.check()
+
+	add := t.sizeOfVal(val)
+	if !t.within(t.size()+1, t.bytes+add) {
+		if t.policy != OverflowDropOldest || !t.within(1, add) {
+			return &OverflowError{Name: t.name, Capacity: t.capacity, Size: t.size()}
+		}
+		for !t.within(t.size()+1, t.bytes+add) {
+			t.evictOldest()
+		}
+	}
+
+	t.pushed = append(t.pushed, val)
+	t.bytes += add
+	t.events = append(t.events, txEvent[T]{val: val, push: true})
+	t.peak = max(t.peak, t.size())
+
+	return nil
+}
+
+// evictOldest drops the bottom staged item.
+// Callers must ensure the staged stack is not empty.
+func (t *tx[T]) evictOldest() {
+	var val T
+	if t.lo < t.hi {
+		val = t.base[t.lo]
+		t.lo++
+	} else {
+		val = t.pushed[0]
+		t.pushed = t.pushed[1:]
+	}
+
+	t.bytes -= t.sizeOfVal(val)
+	t.evicted = append(t.evicted, val)
+}
+
+func (t *tx[T]) Pop() (T, error) {
+	t.check()
+
+	var val T
+	switch {
+	case len(t.pushed) > 0:
+		val = t.pushed[len(t.pushed)-1]
+		t.pushed = t.pushed[:len(t.pushed)-1]
+	case t.hi > t.lo:
+		t.hi--
+		val = t.base[t.hi]
+	default:
+		return val, &UnderflowError{Name: t.name, Capacity: t.capacity}
+	}
+
+	t.bytes -= t.sizeOfVal(val)
+	t.events = append(t.events, txEvent[T]{val: val})
+
+	return val, nil
+}
+
+func (t *tx[T]) Peek() (T, error) {
+	t.check()
+
+	switch {
+	case len(t.pushed) > 0:
+		return t.pushed[len(t.pushed)-1], nil
+	case t.hi > t.lo:
+		return t.base[t.hi-1], nil
+	default:
+		var zero T
+		return zero, &UnderflowError{Name: t.name, Capacity: t.capacity}
+	}
+}
+
+func (t *tx[T]) Size() int {
+	t.check()
+
+	return t.size()
+}
+
+// changed reports whether the transaction modified the staged stack.
+func (t *tx[T]) changed() bool {
+	return len(t.events) > 0 || len(t.evicted) > 0
+}
+
+// report records the events of the transaction on s for the statistics and
+// callbacks. Callers must hold the write lock of s.
+func (t *tx[T]) report(s *stack[T]) {
+	for _, ev := range t.events {
+		if ev.push {
+			s.didPush(ev.val)
+		} else {
+			s.didPop(ev.val)
+		}
+	}
+	if s.onEvict != nil {
+		s.evicted = append(s.evicted, t.evicted...)
+	}
+}
+
+func (s *stack[T]) Transaction(fn func(tx TxStack[T]) error) error {
+	s.lock()
+	defer s.release()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+
+	t := newTx(s, s.items, s.capacity)
+	err := fn(t)
+	t.done = true
+	if err != nil || !t.changed() {
+		return err
+	}
+
+	// Shift the surviving items down over any evicted ones, then append the
+	// staged pushes.
+	kept := copy(s.items, s.items[t.lo:t.hi])
+	clear(s.items[kept:])
+	s.items = append(s.items[:kept], t.pushed...)
+	s.reindex()
+
+	t.report(s)
+	s.noteDepth(t.peak)
+	s.broadcast()
+
+	return nil
+}
+
+func (s *sharded[T]) Transaction(fn func(tx TxStack[T]) error) error {
+	s.lockAll()
+	defer s.notify()
+	defer s.unlockAll()
+
+	if err := s.sealed(); err != nil {
+		return err
+	}
+
+	t := newTx(s.shards[0], s.gather(), s.capacity)
+	err := fn(t)
+	t.done = true
+	if err != nil || !t.changed() {
+		return err
+	}
+
+	s.scatter(append(t.base[t.lo:t.hi], t.pushed...))
+	t.report(s.shards[0])
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTransaction(t *testing.T) {
+	t.Run("commit", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2, 3}))
+
+		err := s.Transaction(func(tx TxStack[int]) error {
+			a, _ := tx.Pop()
+			b, _ := tx.Pop()
+			if err := tx.Push(a + b); err != nil {
+				return err
+			}
+			if top, _ := tx.Peek(); top != 5 {
+				t.Errorf("Peek() inside transaction = %d, want 5", top)
+			}
+			if size := tx.Size(); size != 2 {
+				t.Errorf("Size() inside transaction = %d, want 2", size)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Transaction() error = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{1, 5}) {
+			t.Errorf("ToSlice() after commit = %v, want [1 5]", got)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		s := New[int](WithItems([]int{1, 2}))
+		errAbort := errors.New("abort")
+
+		err := s.Transaction(func(tx TxStack[int]) error {
+			_, _ = tx.Pop()
+			_, _ = tx.Pop()
+			_ = tx.Push(9)
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Errorf("Transaction() error = %v, want errAbort", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("ToSlice() after rollback = %v, want [1 2]", got)
+		}
+		if st := s.Stats(); st.Pops != 0 || st.Pushes != 0 {
+			t.Errorf("Stats() after rollback = %+v, want no pushes or pops", st)
+		}
+	})
+
+	t.Run("staged view", func(t *testing.T) {
+		s := New[int](WithItems([]int{1}))
+
+		_ = s.Transaction(func(tx TxStack[int]) error {
+			_ = tx.Push(2)
+			if v, _ := tx.Pop(); v != 2 {
+				t.Errorf("Pop() of staged push = %d, want 2", v)
+			}
+			if v, _ := tx.Pop(); v != 1 {
+				t.Errorf("Pop() of committed item = %d, want 1", v)
+			}
+			if _, err := tx.Pop(); !errors.Is(err, ErrUnderflow) {
+				t.Errorf("Pop() on empty staged stack error = %v, want ErrUnderflow", err)
+			}
+			if _, err := tx.Peek(); !errors.Is(err, ErrUnderflow) {
+				t.Errorf("Peek() on empty staged stack error = %v, want ErrUnderflow", err)
+			}
+			return nil
+		})
+		if !s.IsEmpty() {
+			t.Errorf("ToSlice() after commit = %v, want []", s.ToSlice())
+		}
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		s := New[int](WithCapacity[int](2), WithItems([]int{1}))
+
+		_ = s.Transaction(func(tx TxStack[int]) error {
+			_ = tx.Push(2)
+			var overflow *OverflowError
+			if err := tx.Push(3); !errors.As(err, &overflow) || overflow.Size != 2 {
+				t.Errorf("Push() on full staged stack error = %v, want *OverflowError with Size 2", err)
+			}
+			return nil
+		})
+		if got := s.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("ToSlice() after commit = %v, want [1 2]", got)
+		}
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		var evicted []int
+		s := New[int](
+			WithCapacity[int](2),
+			WithOverflowPolicy[int](OverflowDropOldest),
+			WithEvictionHandler(func(v int) { evicted = append(evicted, v) }),
+			WithItems([]int{1, 2}),
+		)
+
+		_ = s.Transaction(func(tx TxStack[int]) error {
+			_ = tx.Push(3)
+			_ = tx.Push(4)
+			_ = tx.Push(5)
+			return nil
+		})
+		if got := s.ToSlice(); !slices.Equal(got, []int{4, 5}) {
+			t.Errorf("ToSlice() after commit = %v, want [4 5]", got)
+		}
+		if !slices.Equal(evicted, []int{1, 2, 3}) {
+			t.Errorf("evicted = %v, want [1 2 3]", evicted)
+		}
+	})
+
+	t.Run("hooks and stats", func(t *testing.T) {
+		obs := &recordingObserver{}
+		s := New[int](WithObserver[int](obs), WithItems([]int{1}))
+
+		_ = s.Transaction(func(tx TxStack[int]) error {
+			_ = tx.Push(2)
+			_ = tx.Push(3)
+			_, _ = tx.Pop()
+			return nil
+		})
+		if st := s.Stats(); st.Pushes != 2 || st.Pops != 1 || st.MaxSize != 3 {
+			t.Errorf("Stats() = %+v, want 2 pushes, 1 pop, MaxSize 3", st)
+		}
+		if s.MaxDepth() != 3 {
+			t.Errorf("MaxDepth() = %d, want 3", s.MaxDepth())
+		}
+		if len(obs.pushed) != 2 || len(obs.popped) != 1 {
+			t.Errorf("observer saw %v pushed and %v popped, want 2 and 1", obs.pushed, obs.popped)
+		}
+	})
+
+	t.Run("use after end", func(t *testing.T) {
+		s := New[int]()
+		var leaked TxStack[int]
+		_ = s.Transaction(func(tx TxStack[int]) error {
+			leaked = tx
+			return nil
+		})
+
+		defer func() {
+			if recover() == nil {
+				t.Error("using a transaction after it ended did not panic")
+			}
+		}()
+		_ = leaked.Push(1)
+	})
+
+	t.Run("panic releases lock", func(t *testing.T) {
+		for name, s := range map[string]Stack[int]{"plain": New[int](), "sharded": NewSharded[int](2)} {
+			func() {
+				defer func() { _ = recover() }()
+				_ = s.Transaction(func(tx TxStack[int]) error {
+					_ = tx.Push(1)
+					panic("boom")
+				})
+			}()
+			if err := s.Push(1); err != nil || s.Size() != 1 {
+				t.Errorf("%s: stack unusable after panicking transaction", name)
+			}
+		}
+	})
+}
+
+func TestShardedTransaction(t *testing.T) {
+	s := NewSharded[int](3, WithCapacity[int](6), WithItems([]int{1, 2, 3, 4}))
+	before := s.ToSlice()
+
+	err := s.Transaction(func(tx TxStack[int]) error {
+		top, _ := tx.Pop()
+		if top != before[len(before)-1] {
+			t.Errorf("Pop() inside transaction = %d, want %d", top, before[len(before)-1])
+		}
+		return tx.Push(top * 10)
+	})
+	if err != nil {
+		t.Fatalf("Transaction() error = %v, want nil", err)
+	}
+
+	want := append(slices.Clone(before[:3]), before[3]*10)
+	if got := s.ToSlice(); !slices.Equal(got, want) {
+		t.Errorf("ToSlice() after commit = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package stack
+
+// itemSet tracks the items on a stack so that duplicates can be refused.
+// See WithUniqueness.
+type itemSet[T any] interface {
+	// contains reports whether val is on the stack.
+	contains(val T) bool
+
+	// add and remove record that a copy of val was pushed or popped.
+	add(val T)
+	remove(val T)
+
+	// reset forgets every item.
+	reset()
+
+	// fresh returns a new, empty set of the same kind.
+	fresh() itemSet[T]
+
+	// distinct returns the items of vals that are neither on the stack nor
+	// earlier in vals, in order.
+	distinct(vals []T) []T
+}
+
+// itemCounts is the itemSet of a stack of comparable items. It counts the
+// copies of each item rather than just recording its presence, so that it
+// stays accurate even if a duplicate is added by an operation that does not
+// check for them, such as Restore.
+type itemCounts[T comparable] map[T]int
+
+func (c itemCounts[T]) contains(val T) bool {
+	return c[val] > 0
+}
+
+func (c itemCounts[T]) add(val T) {
+	c[val]++
+}
+
+func (c itemCounts[T]) remove(val T) {
+	if c[val] <= 1 {
+		delete(c, val)
+	} else {
+		c[val]--
+	}
+}
+
+func (c itemCounts[T]) reset() {
+	clear(c)
+}
+
+func (c itemCounts[T]) fresh() itemSet[T] {
+	return make(itemCounts[T])
+}
+
+func (c itemCounts[T]) distinct(vals []T) []T {
+	seen := make(map[T]struct{}, len(vals))
+	kept := make([]T, 0, len(vals))
+	for _, val := range vals {
+		if _, dup := seen[val]; dup || c[val] > 0 {
+			continue
+		}
+		seen[val] = struct{}{}
+		kept = append(kept, val)
+	}
+
+	return kept
+}
+
+// duplicate reports whether val must not be pushed because it is already on
+// the stack, and returns the error to report for it: ErrDuplicate, or nil if
+// duplicates are skipped silently. Callers must hold the write lock.
+func (s *stack[T]) duplicate(val T) (bool, error) {
+	if s.unique == nil || !s.unique.contains(val) {
+		return false, nil
+	}
+	if s.rejectDuplicates {
+		return true, ErrDuplicate
+	}
+
+	return true, nil
+}
+
+// repeats reports whether vals holds the same item more than once on a stack
+// created with WithUniqueness.
+func (s *stack[T]) repeats(vals []T) bool {
+	return s.unique != nil && len(s.unique.distinct(vals)) < len(vals)
+}
+
+// distinct is like duplicate for a batch of items: it returns vals without
+// the items that must not be pushed, or ErrDuplicate if there are any and
+// duplicates are rejected. Callers must hold the write lock.
+func (s *stack[T]) distinct(vals []T) ([]T, error) {
+	if s.unique == nil {
+		return vals, nil
+	}
+
+	kept := s.unique.distinct(vals)
+	if len(kept) < len(vals) && s.rejectDuplicates {
+		return nil, ErrDuplicate
+	}
+
+	return kept, nil
+}
@@ -0,0 +1,97 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWithUniqueness(t *testing.T) {
+	t.Run("reject", func(t *testing.T) {
+		s := New[int](WithUniqueness[int](true), WithItems([]int{1, 2}))
+
+		if err := s.Push(1); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("Push(1) = %v, want ErrDuplicate", err)
+		}
+		if s.TryPush(2) {
+			t.Error("TryPush(2) = true, want false")
+		}
+		if err := s.Dup(); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("Dup() = %v, want ErrDuplicate", err)
+		}
+		if err := s.PushBottom(2); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("PushBottom(2) = %v, want ErrDuplicate", err)
+		}
+		if err := s.PushMany(3, 4, 3); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("PushMany(3, 4, 3) = %v, want ErrDuplicate", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("items = %v, want [1 2]", got)
+		}
+		if got := s.Stats().Overflows; got != 0 {
+			t.Errorf("Overflows = %d, want 0", got)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		s := New[int](WithUniqueness[int](false), WithItems([]int{1, 2}))
+
+		if err := s.Push(1); err != nil {
+			t.Errorf("Push(1) = %v, want nil", err)
+		}
+		if s.TryPush(2) {
+			t.Error("TryPush(2) = true, want false")
+		}
+		if err := s.PushMany(3, 2, 4, 3); err != nil {
+			t.Errorf("PushMany(3, 2, 4, 3) = %v, want nil", err)
+		}
+		if got := s.ToSlice(); !slices.Equal(got, []int{1, 2, 3, 4}) {
+			t.Errorf("items = %v, want [1 2 3 4]", got)
+		}
+	})
+
+	t.Run("pop and clear", func(t *testing.T) {
+		s := New[int](WithUniqueness[int](true), WithItems([]int{1, 2}))
+
+		_, _ = s.Pop()
+		if err := s.Push(2); err != nil {
+			t.Errorf("Push(2) after Pop = %v, want nil", err)
+		}
+		s.Clear()
+		if err := s.PushMany(1, 2); err != nil {
+			t.Errorf("PushMany(1, 2) after Clear = %v, want nil", err)
+		}
+		if err := s.Push(1); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("Push(1) = %v, want ErrDuplicate", err)
+		}
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		s := New[int](WithUniqueness[int](true), WithItems([]int{1}))
+		c := s.Clone()
+
+		if err := c.Push(1); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("clone Push(1) = %v, want ErrDuplicate", err)
+		}
+		_, _ = c.Pop()
+		if err := s.Push(1); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("Push(1) after clone Pop = %v, want ErrDuplicate", err)
+		}
+	})
+
+	t.Run("from slice", func(t *testing.T) {
+		_, err := NewFromSlice([]int{1, 1}, WithUniqueness[int](false))
+		if !errors.Is(err, ErrDuplicate) {
+			t.Errorf("NewFromSlice = %v, want ErrDuplicate", err)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("NewSharded did not panic")
+			}
+		}()
+		NewSharded(2, WithUniqueness[int](true))
+	})
+}